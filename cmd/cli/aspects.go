@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+func aspectsCommand(args []string) error {
+	fs := flag.NewFlagSet("aspects", flag.ExitOnError)
+	business := fs.String("business", "", "id or url of a stored business")
+	dataDir := fs.String("data", "data", "data directory of the store")
+	period := fs.String("period", "month", "period of the trend: day, week, month or year")
+	aspectsFile := fs.String("aspects", "", "JSON file of aspects with name and keywords (default built-in aspects)")
+	var filters filterFlags
+	fs.Var(&filters, "filter", "review filter as type=value, may be repeated")
+	fs.Parse(args)
+
+	if *business == "" {
+		return fmt.Errorf("aspects: -business is required")
+	}
+
+	var aspects []yelp.Aspect
+	if *aspectsFile != "" {
+		f, err := os.Open(*aspectsFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := json.NewDecoder(f).Decode(&aspects); err != nil {
+			return fmt.Errorf("aspects: %s: %v", *aspectsFile, err)
+		}
+	}
+
+	store, err := yelp.OpenStore(*dataDir)
+	if err != nil {
+		return err
+	}
+	b, err := store.FindBusiness(*business)
+	if err != nil {
+		return err
+	}
+
+	b.FilterReviews(filters)
+	report, err := b.AspectReport(aspects, *period)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
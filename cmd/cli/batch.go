@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+func batchCommand(args []string) error {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	file := fs.String("file", "", "file with one business url per line")
+	dataDir := fs.String("data", "data", "data directory for the store and job queue")
+	workers := fs.Int("workers", 4, "number of businesses scraped at once")
+	concurrency := fs.Int("concurrency", 8, "maximum page fetches in flight across businesses")
+	rate := fs.Float64("rate", 2, "maximum page fetches per second across businesses")
+	fs.Parse(args)
+
+	urls := fs.Args()
+	if *file != "" {
+		lines, err := readLines(*file)
+		if err != nil {
+			return err
+		}
+		urls = append(urls, lines...)
+	}
+
+	store, err := yelp.OpenStore(*dataDir)
+	if err != nil {
+		return err
+	}
+	queue, err := yelp.OpenJobQueue(filepath.Join(*dataDir, "jobs.json"), store)
+	if err != nil {
+		return err
+	}
+
+	for _, url := range urls {
+		if _, err := queue.Enqueue(url); err != nil {
+			return err
+		}
+	}
+
+	yelp.SetMaxConcurrentFetches(*concurrency)
+	yelp.SetRateLimit(*rate)
+	s := queue.Run(*workers)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "JOB\tSTATUS\tRATING\tREVIEWS\tURL\tERROR")
+	for _, j := range queue.Jobs() {
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%d\t%s\t%s\n", j.ID, j.Status, j.Rating, j.ReviewCount, j.URL, j.Error)
+	}
+	w.Flush()
+
+	fmt.Printf("Total jobs: %d, done: %d, failed: %d, pending: %d\n", s.Total, s.Done, s.Failed, s.Pending)
+	return nil
+}
+
+// readLines returns the non-empty, non-comment lines of a file.
+func readLines(path string) (lines []string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
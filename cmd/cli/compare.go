@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+func compareCommand(args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	cached := fs.Bool("cached", false, "compare stored businesses by id or url instead of fetching them")
+	dataDir := fs.String("data", "data", "data directory of the store, with -cached")
+	model := fs.String("model", "", "rating model")
+	asCSV := fs.Bool("csv", false, "write CSV instead of a table")
+	var filters filterFlags
+	fs.Var(&filters, "filter", "review filter as type=value, may be repeated")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s compare [flags] <url>...\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() < 2 {
+		return fmt.Errorf("compare: at least 2 businesses are required")
+	}
+
+	var businesses []yelp.LocalBusiness
+	if *cached {
+		store, err := yelp.OpenStore(*dataDir)
+		if err != nil {
+			return err
+		}
+		for _, arg := range fs.Args() {
+			b, err := store.FindBusiness(arg)
+			if err != nil {
+				return err
+			}
+			businesses = append(businesses, b)
+		}
+	} else {
+		var err error
+		if businesses, err = yelp.FetchBusinesses(fs.Args(), yelp.FetchOptions{}); err != nil {
+			return err
+		}
+	}
+
+	report, err := yelp.Compare(businesses, filters, *model)
+	if err != nil {
+		return err
+	}
+	if *asCSV {
+		return yelp.WriteComparisonCSV(os.Stdout, report)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tYELP\tRATING\tREVIEWS\t1-5 STARS\tPER MONTH\tRECENT\tLOCAL\tSENTIMENT")
+	for _, c := range report.Businesses {
+		fmt.Fprintf(w, "%s\t%.1f\t%.2f\t%d\t%v\t%.1f\t%.1f\t%.0f%%\t%+.2f\n",
+			c.Name, c.AggregateRating, c.Rating, c.ReviewCount, c.Distribution,
+			c.ReviewsPerMonth, c.RecentReviewsPerMonth, c.LocalShare*100, c.Sentiment)
+	}
+	return w.Flush()
+}
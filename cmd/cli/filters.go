@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+// filterFlags collects repeated -filter type=value flags.
+type filterFlags []yelp.ReviewFilter
+
+func (f *filterFlags) String() string {
+	parts := make([]string, len(*f))
+	for i, filter := range *f {
+		parts[i] = filter.Type + "=" + filter.Value
+	}
+	return strings.Join(parts, ",")
+}
+
+func (f *filterFlags) Set(s string) error {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("filter %q must be of the form type=value", s)
+	}
+	*f = append(*f, yelp.ReviewFilter{Type: parts[0], Value: parts[1]})
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+func graphCommand(args []string) error {
+	fs := flag.NewFlagSet("graph", flag.ExitOnError)
+	dataDir := fs.String("data", "data", "data directory of the store")
+	format := fs.String("format", "json", "output format: json, graphml or dot")
+	minShared := fs.Int("min-shared", 1, "shared reviewers needed to connect two businesses")
+	minCoReviews := fs.Int("min-co-reviews", 2, "businesses two authors must share to be clustered")
+	fs.Parse(args)
+
+	store, err := yelp.OpenStore(*dataDir)
+	if err != nil {
+		return err
+	}
+	businesses, err := store.Businesses()
+	if err != nil {
+		return err
+	}
+
+	g := yelp.BuildReviewerGraph(businesses, yelp.GraphOptions{
+		MinShared:    *minShared,
+		MinCoReviews: *minCoReviews,
+	})
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(g)
+	case "graphml":
+		return g.WriteGraphML(os.Stdout)
+	case "dot":
+		return g.WriteDOT(os.Stdout)
+	}
+	return fmt.Errorf("graph: unsupported format %s", *format)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+func historyCommand(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	business := fs.String("business", "", "id or url of a stored business")
+	dataDir := fs.String("data", "data", "data directory of the store")
+	window := fs.Int("window", 30, "rolling window in days")
+	asCSV := fs.Bool("csv", false, "write CSV instead of JSON")
+	var filters filterFlags
+	fs.Var(&filters, "filter", "review filter as type=value, may be repeated")
+	fs.Parse(args)
+
+	if *business == "" {
+		return fmt.Errorf("history: -business is required")
+	}
+
+	store, err := yelp.OpenStore(*dataDir)
+	if err != nil {
+		return err
+	}
+	b, err := store.FindBusiness(*business)
+	if err != nil {
+		return err
+	}
+
+	b.FilterReviews(filters)
+	points := b.RatingHistory(*window)
+	if *asCSV {
+		return yelp.WriteRatingHistoryCSV(os.Stdout, points)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(points)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+func keywordsCommand(args []string) error {
+	fs := flag.NewFlagSet("keywords", flag.ExitOnError)
+	business := fs.String("business", "", "id or url of a stored business")
+	dataDir := fs.String("data", "data", "data directory of the store")
+	ngram := fs.Int("ngram", 2, "maximum number of words per keyword")
+	limit := fs.Int("limit", 20, "number of keywords per list")
+	minCount := fs.Int("min-count", 2, "minimum number of occurrences of a keyword")
+	var filters filterFlags
+	fs.Var(&filters, "filter", "review filter as type=value, may be repeated")
+	fs.Parse(args)
+
+	if *business == "" {
+		return fmt.Errorf("keywords: -business is required")
+	}
+
+	store, err := yelp.OpenStore(*dataDir)
+	if err != nil {
+		return err
+	}
+	b, err := store.FindBusiness(*business)
+	if err != nil {
+		return err
+	}
+	businesses, err := store.Businesses()
+	if err != nil {
+		return err
+	}
+
+	b.FilterReviews(filters)
+	report := yelp.NewCorpus(businesses, *ngram).Keywords(&b, yelp.KeywordOptions{
+		NGram:    *ngram,
+		Limit:    *limit,
+		MinCount: *minCount,
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, list := range []struct {
+		name     string
+		keywords []yelp.Keyword
+	}{
+		{"all", report.All},
+		{"positive", report.Positive},
+		{"negative", report.Negative},
+	} {
+		fmt.Fprintf(w, "%s reviews\tCOUNT\tSCORE\n", list.name)
+		for _, k := range list.keywords {
+			fmt.Fprintf(w, "  %s\t%d\t%.3f\n", k.Term, k.Count, k.Score)
+		}
+		fmt.Fprintln(w)
+	}
+	return w.Flush()
+}
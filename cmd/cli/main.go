@@ -1,15 +1,67 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 
 	"github.com/Taik/yelp-reviews/yelp"
 )
 
+const defaultURL = "http://www.yelp.com/biz/sal-kris-and-charlies-deli-astoria"
+
+// commands maps subcommand names to their implementation.
+var commands = map[string]func(args []string) error{
+	"aspects":  aspectsCommand,
+	"batch":    batchCommand,
+	"compare":  compareCommand,
+	"graph":    graphCommand,
+	"history":  historyCommand,
+	"keywords": keywordsCommand,
+	"rating":   ratingCommand,
+	"reviews":  reviewsCommand,
+	"search":   searchCommand,
+	"summary":  summaryCommand,
+	"velocity": velocityCommand,
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "usage: %s <%s> [flags]\n", os.Args[0], strings.Join(names, "|"))
+}
+
 func main() {
-	url := "http://www.yelp.com/biz/sal-kris-and-charlies-deli-astoria"
-	business, _ := yelp.NewBusiness(url)
+	name, args := "rating", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd(args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func ratingCommand(args []string) error {
+	fs := flag.NewFlagSet("rating", flag.ExitOnError)
+	url := fs.String("url", defaultURL, "business url")
+	fs.Parse(args)
+
+	business, err := yelp.NewBusiness(*url)
+	if err != nil {
+		return err
+	}
 
 	business.FetchReviews()
 	var reviews []yelp.Review
@@ -30,4 +82,11 @@ func main() {
 		sum/float64(len(reviews)),
 		len(uniqueLocations),
 	)
+
+	origins := business.RatingByOrigin()
+	for _, origin := range []string{yelp.OriginSameCity, yelp.OriginSameRegion, yelp.OriginOutOfRegion, yelp.OriginUnknown} {
+		s := origins[origin]
+		fmt.Printf("  %s: %d reviews, rating: %f\n", origin, s.Count, s.Mean)
+	}
+	return nil
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+// excerptLength is the number of characters of review text printed per hit.
+const excerptLength = 80
+
+func reviewsCommand(args []string) error {
+	fs := flag.NewFlagSet("reviews", flag.ExitOnError)
+	query := fs.String("q", "", `words the reviews must contain, with "quoted phrases"`)
+	dataDir := fs.String("data", "data", "data directory of the store")
+	business := fs.String("business", "", "id or url of a stored business")
+	author := fs.String("author", "", "author id")
+	minRating := fs.Float64("min-rating", 0, "minimum rating")
+	maxRating := fs.Float64("max-rating", 0, "maximum rating")
+	since := fs.String("since", "", "oldest review date, YYYY-MM-DD")
+	until := fs.String("until", "", "newest review date, YYYY-MM-DD")
+	limit := fs.Int("limit", 20, "maximum number of reviews")
+	fs.Parse(args)
+
+	q := yelp.ReviewQuery{
+		Text:      *query,
+		AuthorID:  *author,
+		MinRating: *minRating,
+		MaxRating: *maxRating,
+		Limit:     *limit,
+	}
+	var err error
+	if *since != "" {
+		if q.Since, err = time.Parse("2006-01-02", *since); err != nil {
+			return fmt.Errorf("reviews: invalid -since: %v", err)
+		}
+	}
+	if *until != "" {
+		if q.Until, err = time.Parse("2006-01-02", *until); err != nil {
+			return fmt.Errorf("reviews: invalid -until: %v", err)
+		}
+	}
+
+	store, err := yelp.OpenStore(*dataDir)
+	if err != nil {
+		return err
+	}
+	if *business != "" {
+		b, err := store.FindBusiness(*business)
+		if err != nil {
+			return err
+		}
+		q.BusinessID = b.ID
+	}
+	index, err := store.ReviewIndex()
+	if err != nil {
+		return err
+	}
+
+	result := index.Search(q)
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SCORE\tBUSINESS\tDATE\tRATING\tREVIEW\tTEXT")
+	for _, h := range result.Hits {
+		text := []rune(strings.Join(strings.Fields(h.Review.Description), " "))
+		if len(text) > excerptLength {
+			text = append(text[:excerptLength], []rune("...")...)
+		}
+		fmt.Fprintf(w, "%.2f\t%s\t%s\t%.0f\t%s\t%s\n",
+			h.Score, h.BusinessName, h.Review.Date.Format("2006-01-02"), h.Review.Rating, h.Review.ID, string(text))
+	}
+	fmt.Fprintf(w, "%d of %d reviews\n", len(result.Hits), result.Total)
+	return w.Flush()
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+func searchCommand(args []string) error {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	term := fs.String("term", "", "search term, e.g. deli")
+	location := fs.String("location", "", "search location, e.g. Astoria, NY")
+	pages := fs.Int("pages", 3, "maximum number of result pages")
+	fetch := fs.Bool("fetch", false, "fetch reviews and recalculate the rating of each result")
+	fs.Parse(args)
+
+	if *location == "" {
+		return fmt.Errorf("search: -location is required")
+	}
+
+	businesses, err := yelp.Search(*term, *location, *pages)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tRATING\tREVIEWS\tURL")
+	for _, stub := range businesses {
+		rating, count := stub.AggregateRating, stub.ReviewCount
+		if *fetch {
+			b, err := yelp.NewBusiness(stub.URL)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to fetch %s: %v\n", stub.URL, err)
+				continue
+			}
+			b.FetchReviews()
+			rating, count = b.CalculateRating(), len(b.Reviews)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%d\t%s\n", stub.ID, stub.Name, rating, count, stub.URL)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+func summaryCommand(args []string) error {
+	fs := flag.NewFlagSet("summary", flag.ExitOnError)
+	business := fs.String("business", "", "id or url of a stored business")
+	dataDir := fs.String("data", "data", "data directory of the store")
+	sentences := fs.Int("sentences", 5, "number of sentences per list")
+	split := fs.Bool("split", false, "separate praise and complaints")
+	var filters filterFlags
+	fs.Var(&filters, "filter", "review filter as type=value, may be repeated")
+	fs.Parse(args)
+
+	if *business == "" {
+		return fmt.Errorf("summary: -business is required")
+	}
+
+	store, err := yelp.OpenStore(*dataDir)
+	if err != nil {
+		return err
+	}
+	b, err := store.FindBusiness(*business)
+	if err != nil {
+		return err
+	}
+
+	b.FilterReviews(filters)
+	summary := b.Summarize(yelp.SummaryOptions{
+		Sentences: *sentences,
+		Split:     *split,
+	})
+
+	printSentences(b.Name, summary.Sentences)
+	if *split {
+		printSentences("Praise", summary.Praise)
+		printSentences("Complaints", summary.Complaints)
+	}
+	return nil
+}
+
+func printSentences(title string, sentences []yelp.SummarySentence) {
+	fmt.Fprintf(os.Stdout, "%s\n", title)
+	for _, s := range sentences {
+		fmt.Fprintf(os.Stdout, "  - %s [%s]\n", s.Text, s.ReviewID)
+	}
+	fmt.Fprintln(os.Stdout)
+}
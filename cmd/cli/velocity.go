@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+func velocityCommand(args []string) error {
+	fs := flag.NewFlagSet("velocity", flag.ExitOnError)
+	business := fs.String("business", "", "id or url of a stored business")
+	dataDir := fs.String("data", "data", "data directory of the store")
+	var filters filterFlags
+	fs.Var(&filters, "filter", "review filter as type=value, may be repeated")
+	fs.Parse(args)
+
+	if *business == "" {
+		return fmt.Errorf("velocity: -business is required")
+	}
+
+	store, err := yelp.OpenStore(*dataDir)
+	if err != nil {
+		return err
+	}
+	b, err := store.FindBusiness(*business)
+	if err != nil {
+		return err
+	}
+
+	b.FilterReviews(filters)
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b.VelocityStats())
+}
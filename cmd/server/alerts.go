@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+
+	"github.com/labstack/echo"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+var alerter *yelp.Alerter
+
+type alertRulesResponse struct {
+	Rules []yelp.AlertRule `json:"rules"`
+}
+
+// alertSinks returns the alert sinks configured through the environment.
+func alertSinks() []yelp.AlertSink {
+	sinks := []yelp.AlertSink{yelp.LogSink{}}
+	if path := os.Getenv("ALERT_FILE"); path != "" {
+		sinks = append(sinks, &yelp.FileSink{Path: path})
+	}
+	if url := os.Getenv("ALERT_WEBHOOK"); url != "" {
+		sinks = append(sinks, yelp.WebhookSink{URL: url})
+	}
+	return sinks
+}
+
+func alertRulesHandle(c echo.Context) error {
+	return c.JSON(http.StatusOK, &alertRulesResponse{
+		Rules: alerter.Rules(),
+	})
+}
+
+func alertRuleSetHandle(c echo.Context) (err error) {
+	decoder := json.NewDecoder(c.Request().Body())
+	rule := yelp.AlertRule{}
+
+	if err = decoder.Decode(&rule); err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+	if err = alerter.SetRule(rule); err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusOK, &alertRulesResponse{
+		Rules: alerter.Rules(),
+	})
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/labstack/echo"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+type aspectsRequest struct {
+	// Business is the ID or URL of a stored business.
+	Business string              `json:"business"`
+	Filters  []yelp.ReviewFilter `json:"filters"`
+	Aspects  []yelp.Aspect       `json:"aspects"`
+	Period   string              `json:"period"`
+}
+
+func aspectsHandle(c echo.Context) (err error) {
+	decoder := json.NewDecoder(c.Request().Body())
+	request := &aspectsRequest{
+		Period: "month",
+	}
+	if err = decoder.Decode(request); err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+
+	b, err := store.FindBusiness(request.Business)
+	if err != nil {
+		return newError(http.StatusNotFound, err)
+	}
+
+	b.FilterReviews(request.Filters)
+	report, err := b.AspectReport(request.Aspects, request.Period)
+	if err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusOK, report)
+}
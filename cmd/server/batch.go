@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/labstack/echo"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+var queue *yelp.JobQueue
+
+type batchRequest struct {
+	URLs []string `json:"urls"`
+}
+
+type batchResponse struct {
+	Summary yelp.JobSummary `json:"summary"`
+	Jobs    []yelp.Job      `json:"jobs"`
+}
+
+func batchCreateHandle(c echo.Context) (err error) {
+	decoder := json.NewDecoder(c.Request().Body())
+	request := &batchRequest{}
+	resp := &batchResponse{}
+
+	if err = decoder.Decode(request); err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+
+	for _, url := range request.URLs {
+		j, err := queue.Enqueue(url)
+		if err != nil {
+			return newError(http.StatusBadRequest, err)
+		}
+		resp.Jobs = append(resp.Jobs, j)
+	}
+
+	resp.Summary = queue.Summary()
+	return c.JSON(http.StatusAccepted, resp)
+}
+
+func batchStatusHandle(c echo.Context) error {
+	resp := &batchResponse{
+		Summary: queue.Summary(),
+		Jobs:    queue.Jobs(),
+	}
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+type compareRequest struct {
+	URLs    []string            `json:"urls"`
+	Filters []yelp.ReviewFilter `json:"filters"`
+	Model   string              `json:"model"`
+	// Cached compares the stored copy of each business instead of fetching it.
+	Cached bool `json:"cached"`
+}
+
+func compareHandle(c echo.Context) (err error) {
+	decoder := json.NewDecoder(c.Request().Body())
+	request := &compareRequest{}
+	err = decoder.Decode(request)
+	if err == nil && len(request.URLs) < 2 {
+		err = fmt.Errorf("at least 2 urls are required")
+	}
+	if err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+
+	var businesses []yelp.LocalBusiness
+	if request.Cached {
+		for _, url := range request.URLs {
+			b, err := store.FindBusiness(url)
+			if err != nil {
+				return newError(http.StatusNotFound, err)
+			}
+			businesses = append(businesses, b)
+		}
+	} else {
+		businesses, err = yelp.FetchBusinesses(request.URLs, yelp.FetchOptions{})
+		if err != nil {
+			return newError(http.StatusBadGateway, err)
+		}
+		for i := range businesses {
+			if err := store.SaveBusiness(&businesses[i]); err != nil {
+				log.Printf("failed to store business %s: %v\n", businesses[i].URL, err)
+			}
+		}
+	}
+
+	report, err := yelp.Compare(businesses, request.Filters, request.Model)
+	if err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusOK, report)
+}
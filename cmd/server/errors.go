@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+)
+
+// errorResponse defines the envelope of every error returned by the server.
+type errorResponse struct {
+	Error apiError `json:"error"`
+}
+
+// apiError defines a failed request. Code is a stable identifier derived from
+// the HTTP status, Message a human readable description.
+type apiError struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+var errorCodes = map[int]string{
+	http.StatusBadRequest:          "bad_request",
+	http.StatusNotFound:            "not_found",
+	http.StatusMethodNotAllowed:    "method_not_allowed",
+	http.StatusConflict:            "conflict",
+	http.StatusInternalServerError: "internal_error",
+	http.StatusBadGateway:          "upstream_error",
+	http.StatusServiceUnavailable:  "unavailable",
+}
+
+// newError returns an error rendered by httpErrorHandler with the given status.
+func newError(status int, err error) *echo.HTTPError {
+	return echo.NewHTTPError(status, err.Error())
+}
+
+// httpErrorHandler renders errors returned by handlers, middleware and the
+// router in the error envelope. Errors other than *echo.HTTPError are
+// internal errors.
+func httpErrorHandler(err error, c echo.Context) {
+	status, message := http.StatusInternalServerError, err.Error()
+	if he, ok := err.(*echo.HTTPError); ok {
+		status, message = he.Code, he.Message
+	}
+	if c.Response().Committed() {
+		return
+	}
+
+	code, ok := errorCodes[status]
+	if !ok {
+		code = "error"
+	}
+	c.JSON(status, &errorResponse{apiError{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}})
+}
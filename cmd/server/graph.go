@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+func graphHandle(c echo.Context) (err error) {
+	businesses, err := store.Businesses()
+	if err != nil {
+		return newError(http.StatusInternalServerError, err)
+	}
+
+	opts := yelp.GraphOptions{}
+	opts.MinShared, _ = strconv.Atoi(c.QueryParam("min_shared"))
+	opts.MinCoReviews, _ = strconv.Atoi(c.QueryParam("min_co_reviews"))
+	g := yelp.BuildReviewerGraph(businesses, opts)
+
+	buf := &bytes.Buffer{}
+	switch format := c.QueryParam("format"); format {
+	case "", "json":
+		return c.JSON(http.StatusOK, g)
+	case "graphml":
+		if err := g.WriteGraphML(buf); err != nil {
+			return err
+		}
+		return c.Blob(http.StatusOK, "application/graphml+xml", buf.Bytes())
+	case "dot":
+		if err := g.WriteDOT(buf); err != nil {
+			return err
+		}
+		return c.Blob(http.StatusOK, "text/vnd.graphviz", buf.Bytes())
+	default:
+		return newError(http.StatusBadRequest, fmt.Errorf("unsupported format %s", format))
+	}
+}
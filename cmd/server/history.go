@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/labstack/echo"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+// historyParams defines the history query parameters that are not review
+// filters.
+var historyParams = map[string]bool{
+	"business":    true,
+	"window_days": true,
+	"format":      true,
+}
+
+type historyResponse struct {
+	Points []yelp.RatingPoint `json:"points"`
+}
+
+// historyHandle returns the rating history of a stored business, e.g.
+// /history?business=ID&window_days=30&format=csv&min_review_length=100.
+// Query parameters other than business, window_days and format are review
+// filters.
+func historyHandle(c echo.Context) error {
+	windowDays, err := queryInt(c, "window_days", 30)
+	if err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+	b, err := store.FindBusiness(c.QueryParam("business"))
+	if err != nil {
+		return newError(http.StatusNotFound, err)
+	}
+
+	resp := &historyResponse{}
+	b.FilterReviews(queryFilters(c, historyParams))
+	resp.Points = b.RatingHistory(windowDays)
+
+	if c.QueryParam("format") == "csv" {
+		buf := &bytes.Buffer{}
+		if err := yelp.WriteRatingHistoryCSV(buf, resp.Points); err != nil {
+			return err
+		}
+		return c.Blob(http.StatusOK, "text/csv", buf.Bytes())
+	}
+	return c.JSON(http.StatusOK, resp)
+}
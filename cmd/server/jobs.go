@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/engine"
+	"github.com/labstack/echo/middleware"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+type jobRequest struct {
+	URL string `json:"url"`
+	yelp.FetchOptions
+}
+
+// jobCreateHandle enqueues a scrape of a business. A scrape of the same
+// business and listings already pending or running is returned instead of a
+// new job.
+func jobCreateHandle(c echo.Context) error {
+	request := &jobRequest{}
+	if err := json.NewDecoder(c.Request().Body()).Decode(request); err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+
+	j, created, err := queue.Submit(request.URL, request.FetchOptions)
+	if err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+	if !created {
+		return c.JSON(http.StatusOK, j)
+	}
+	return c.JSON(http.StatusAccepted, j)
+}
+
+func jobHandle(c echo.Context) error {
+	j, ok := queue.Job(c.Param("id"))
+	if !ok {
+		return newError(http.StatusNotFound, fmt.Errorf("job %s not found", c.Param("id")))
+	}
+	return c.JSON(http.StatusOK, j)
+}
+
+// jobCancelHandle cancels a pending or running job.
+func jobCancelHandle(c echo.Context) error {
+	if _, ok := queue.Job(c.Param("id")); !ok {
+		return newError(http.StatusNotFound, fmt.Errorf("job %s not found", c.Param("id")))
+	}
+	j, err := queue.Cancel(c.Param("id"))
+	if err != nil {
+		return newError(http.StatusConflict, err)
+	}
+	return c.JSON(http.StatusAccepted, j)
+}
+
+// eventKeepAlive is the interval of the comments sent to keep idle event
+// streams open.
+const eventKeepAlive = 15 * time.Second
+
+// writeEvent writes a server-sent event with a JSON payload and flushes it.
+func writeEvent(res engine.Response, name string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", name, payload); err != nil {
+		return err
+	}
+	flush(res)
+	return nil
+}
+
+// flush sends buffered output to the client. Event streams are not
+// compressed, see gzipExcept.
+func flush(res engine.Response) {
+	if f, ok := res.Writer().(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// gzipExcept compresses responses except those of the given routes, such as
+// event streams, which must reach the client as they are written.
+func gzipExcept(paths ...string) echo.MiddlewareFunc {
+	skip := map[string]bool{}
+	for _, p := range paths {
+		skip[p] = true
+	}
+	gzip := middleware.Gzip()
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		compressed := gzip(next)
+		return func(c echo.Context) error {
+			if skip[c.Path()] {
+				return next(c)
+			}
+			return compressed(c)
+		}
+	}
+}
+
+// jobEventsHandle streams the progress of a job as server-sent events: a
+// "job" event with its current state, an event per listing page started,
+// parsed or failed, a "done" event once its reviews are fetched, and a final
+// "result" event with the finished job.
+func jobEventsHandle(c echo.Context) error {
+	id := c.Param("id")
+	j, ok := queue.Job(id)
+	if !ok {
+		return newError(http.StatusNotFound, fmt.Errorf("job %s not found", id))
+	}
+	events, stop, err := queue.Watch(id)
+	if err != nil {
+		return newError(http.StatusNotFound, err)
+	}
+	defer stop()
+
+	res := c.Response()
+	res.Header().Set("Content-Type", "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.WriteHeader(http.StatusOK)
+
+	if err := writeEvent(res, "job", j); err != nil {
+		return nil
+	}
+
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				j, _ = queue.Job(id)
+				writeEvent(res, "result", j)
+				return nil
+			}
+			if err := writeEvent(res, e.Type, e); err != nil {
+				return nil
+			}
+		case <-keepAlive.C:
+			// A failed write means the client went away.
+			if _, err := io.WriteString(res, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			flush(res)
+		}
+	}
+}
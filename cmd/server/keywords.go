@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/labstack/echo"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+type keywordsRequest struct {
+	// Business is the ID or URL of a stored business.
+	Business string              `json:"business"`
+	Filters  []yelp.ReviewFilter `json:"filters"`
+	yelp.KeywordOptions
+}
+
+func keywordsHandle(c echo.Context) (err error) {
+	decoder := json.NewDecoder(c.Request().Body())
+	request := &keywordsRequest{}
+	if err = decoder.Decode(request); err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+
+	b, err := store.FindBusiness(request.Business)
+	if err != nil {
+		return newError(http.StatusNotFound, err)
+	}
+	businesses, err := store.Businesses()
+	if err != nil {
+		return newError(http.StatusInternalServerError, err)
+	}
+
+	if request.NGram <= 0 {
+		request.NGram = 2
+	}
+	b.FilterReviews(request.Filters)
+	report := yelp.NewCorpus(businesses, request.NGram).Keywords(&b, request.KeywordOptions)
+	return c.JSON(http.StatusOK, report)
+}
@@ -3,8 +3,11 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
 
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/engine/standard"
@@ -14,52 +17,157 @@ import (
 )
 
 type yelpReviewRequest struct {
-	URL     string              `json:"url"`
-	Filters []yelp.ReviewFilter `json:"filters"`
+	URL            string              `json:"url"`
+	Filters        []yelp.ReviewFilter `json:"filters"`
+	Model          string              `json:"model"`
+	EnrichAuthors  bool                `json:"enrich_authors"`
+	NotRecommended bool                `json:"not_recommended"`
+	Languages      []string            `json:"languages"`
 }
 
 type yelpReviewResponse struct {
-	Status      string `json:"status"`
-	Message     string `json:"msg,omitempty"`
-	Rating      string `json:"rating"`
-	ReviewCount int    `json:"review_count"`
+	Rating         string                      `json:"rating"`
+	ReviewCount    int                         `json:"review_count"`
+	MatchesFilters bool                        `json:"matches_filters"`
+	OwnerResponses yelp.ResponseStats          `json:"owner_responses"`
+	ReviewUpdates  yelp.UpdateStats            `json:"review_updates"`
+	Recommendation yelp.RecommendationStats    `json:"recommendation"`
+	Languages      map[string]yelp.RatingStats `json:"languages"`
+	Origins        map[string]yelp.RatingStats `json:"origins"`
+	Sentiment      yelp.SentimentStats         `json:"sentiment"`
+	Business       *yelp.LocalBusiness         `json:"business,omitempty"`
 }
 
+var store *yelp.Store
+
 func yelpReviewHandle(c echo.Context) (err error) {
 	decoder := json.NewDecoder(c.Request().Body())
 	request := &yelpReviewRequest{}
-	resp := &yelpReviewResponse{
-		Status: "OK",
-	}
+	resp := &yelpReviewResponse{}
 
-	err = decoder.Decode(request)
-	if err != nil {
-		resp.Status = "ERROR"
-		resp.Message = err.Error()
-		return c.JSON(http.StatusBadRequest, resp)
+	if err = decoder.Decode(request); err != nil {
+		return newError(http.StatusBadRequest, err)
 	}
 
 	b, err := yelp.NewBusiness(request.URL)
 	if err != nil {
-		resp.Status = "ERROR"
-		resp.Message = err.Error()
-		return c.JSON(http.StatusBadRequest, resp)
+		return newError(http.StatusBadRequest, err)
 	}
 
-	b.FetchReviews()
+	err = b.FetchReviewsWithOptions(yelp.FetchOptions{
+		NotRecommended: request.NotRecommended,
+		Languages:      request.Languages,
+	})
+	if err != nil {
+		return err
+	}
+	if request.EnrichAuthors {
+		b.EnrichAuthors()
+	}
+	if err := store.SaveBusiness(&b); err != nil {
+		log.Printf("failed to store business %s: %v\n", b.URL, err)
+	}
+	resp.Recommendation = b.CompareRecommendation()
 	b.FilterReviews(request.Filters)
 
-	resp.Rating = fmt.Sprintf("%.2f", b.CalculateRating())
+	rating, err := b.CalculateRatingWith(request.Model)
+	if err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+
+	resp.Rating = fmt.Sprintf("%.2f", rating)
 	resp.ReviewCount = len(b.Reviews)
+	resp.MatchesFilters = b.MatchesFilters(request.Filters)
+	resp.OwnerResponses = b.OwnerResponseStats()
+	resp.ReviewUpdates = b.ReviewUpdateStats()
+	resp.Languages = b.RatingByLanguage()
+	resp.Origins = b.RatingByOrigin()
+	resp.Sentiment = b.SentimentStats()
+
+	b.Reviews = nil
+	resp.Business = &b
 
 	return c.JSON(http.StatusOK, resp)
 }
 
+// envInt returns the integer environment variable key, or def if unset or invalid.
+func envInt(key string, def int) int {
+	n, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return n
+}
+
 func main() {
+	dataDir := os.Getenv("DATA_DIR")
+	if dataDir == "" {
+		dataDir = "data"
+	}
+
+	var err error
+	store, err = yelp.OpenStore(dataDir)
+	if err != nil {
+		log.Fatalf("failed to open store %s: %v\n", dataDir, err)
+	}
+
+	yelp.SetMaxConcurrentFetches(envInt("FETCH_CONCURRENCY", 8))
+	if rate, err := strconv.ParseFloat(os.Getenv("FETCH_RATE"), 64); err == nil {
+		yelp.SetRateLimit(rate)
+	}
+
+	queue, err = yelp.OpenJobQueue(filepath.Join(dataDir, "jobs.json"), store)
+	if err != nil {
+		log.Fatalf("failed to open job queue: %v\n", err)
+	}
+	queue.Start(envInt("BATCH_WORKERS", 2))
+
+	if os.Getenv("SCHEDULER") != "" {
+		scheduler, err = yelp.OpenScheduler(store)
+		if err != nil {
+			log.Fatalf("failed to open scheduler: %v\n", err)
+		}
+		alerter, err = yelp.OpenAlerter(store, alertSinks()...)
+		if err != nil {
+			log.Fatalf("failed to open alerter: %v\n", err)
+		}
+		scheduler.OnRefresh = func(b *yelp.LocalBusiness, snap yelp.Snapshot) {
+			alerter.Evaluate(b, snap)
+		}
+		scheduler.Start()
+	}
+
 	e := echo.New()
-	e.Use(middleware.Recover(), middleware.Logger(), middleware.Gzip())
+	e.SetHTTPErrorHandler(httpErrorHandler)
+	e.Use(middleware.Recover(), middleware.Logger(), gzipExcept("/jobs/:id/events"))
 
 	e.POST("/", yelpReviewHandle)
+	e.GET("/search", searchHandle)
+	e.POST("/compare", compareHandle)
+	e.GET("/history", historyHandle)
+	e.POST("/aspects", aspectsHandle)
+	e.POST("/velocity", velocityHandle)
+	e.POST("/keywords", keywordsHandle)
+	e.POST("/summary", summaryHandle)
+	e.GET("/graph", graphHandle)
+	e.GET("/reviews/search", reviewSearchHandle)
+	e.GET("/businesses", businessListHandle)
+	e.GET("/businesses/:id", businessHandle)
+	e.GET("/businesses/:id/reviews", businessReviewsHandle)
+	e.GET("/businesses/:id/rating", businessRatingHandle)
+	e.GET("/authors/:id", authorHandle)
+	e.POST("/batch", batchCreateHandle)
+	e.GET("/batch", batchStatusHandle)
+	e.POST("/jobs", jobCreateHandle)
+	e.GET("/jobs/:id", jobHandle)
+	e.GET("/jobs/:id/events", jobEventsHandle)
+	e.DELETE("/jobs/:id", jobCancelHandle)
+	e.GET("/schedule", scheduleStatusHandle, schedulerRequired)
+	e.POST("/schedule", scheduleTrackHandle, schedulerRequired)
+	e.DELETE("/schedule", scheduleUntrackHandle, schedulerRequired)
+	e.POST("/schedule/groups", scheduleGroupHandle, schedulerRequired)
+	e.GET("/alerts/rules", alertRulesHandle, schedulerRequired)
+	e.POST("/alerts/rules", alertRuleSetHandle, schedulerRequired)
 
 	port := os.Getenv("PORT")
 	if port == "" {
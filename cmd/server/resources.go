@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// reservedParams defines the review listing query parameters that are not
+// review filters.
+var reservedParams = map[string]bool{
+	"page":     true,
+	"per_page": true,
+	"sort":     true,
+	"model":    true,
+}
+
+// businessResource defines a stored business without its reviews.
+type businessResource struct {
+	*yelp.LocalBusiness
+	ScrapedReviews int `json:"scraped_reviews"`
+}
+
+type businessListResponse struct {
+	Businesses []businessResource `json:"businesses"`
+}
+
+type reviewListResponse struct {
+	BusinessID string        `json:"business_id"`
+	Total      int           `json:"total"`
+	Page       int           `json:"page"`
+	PerPage    int           `json:"per_page"`
+	Reviews    []yelp.Review `json:"reviews"`
+}
+
+type ratingResponse struct {
+	BusinessID  string              `json:"business_id"`
+	Model       string              `json:"model"`
+	Filters     []yelp.ReviewFilter `json:"filters"`
+	Rating      float64             `json:"rating"`
+	ReviewCount int                 `json:"review_count"`
+}
+
+// authorReview defines a stored review written by an author.
+type authorReview struct {
+	BusinessID   string      `json:"business_id"`
+	BusinessName string      `json:"business_name"`
+	Review       yelp.Review `json:"review"`
+}
+
+type authorResponse struct {
+	yelp.Author
+	Reviews []authorReview `json:"reviews"`
+}
+
+func newBusinessResource(b yelp.LocalBusiness) businessResource {
+	r := businessResource{LocalBusiness: &b, ScrapedReviews: len(b.Reviews)}
+	b.Reviews = nil
+	return r
+}
+
+// queryFilters returns the review filters given as query parameters other
+// than the reserved ones, e.g. ?min_review_length=100&recommendation=recommended.
+func queryFilters(c echo.Context, reserved map[string]bool) (filters []yelp.ReviewFilter) {
+	params := c.QueryParams()
+	names := make([]string, 0, len(params))
+	for name := range params {
+		if !reserved[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, value := range params[name] {
+			filters = append(filters, yelp.ReviewFilter{Type: name, Value: value})
+		}
+	}
+	return filters
+}
+
+// queryInt returns the positive integer query parameter name, or def if unset.
+func queryInt(c echo.Context, name string, def int) (int, error) {
+	v := c.QueryParam(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, v)
+	}
+	return n, nil
+}
+
+// storedBusiness returns the stored business named by the :id path parameter.
+func storedBusiness(c echo.Context) (yelp.LocalBusiness, error) {
+	b, err := store.FindBusiness(c.Param("id"))
+	if err != nil {
+		return b, newError(http.StatusNotFound, err)
+	}
+	return b, nil
+}
+
+func businessListHandle(c echo.Context) error {
+	businesses, err := store.Businesses()
+	if err != nil {
+		return err
+	}
+
+	resp := &businessListResponse{Businesses: []businessResource{}}
+	for _, b := range businesses {
+		resp.Businesses = append(resp.Businesses, newBusinessResource(b))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func businessHandle(c echo.Context) error {
+	b, err := storedBusiness(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, newBusinessResource(b))
+}
+
+func businessReviewsHandle(c echo.Context) error {
+	b, err := storedBusiness(c)
+	if err != nil {
+		return err
+	}
+
+	page, err := queryInt(c, "page", 1)
+	if err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+	perPage, err := queryInt(c, "per_page", defaultPerPage)
+	if err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+	sortKey := c.QueryParam("sort")
+	if sortKey == "" {
+		sortKey = "-date"
+	}
+
+	b.FilterReviews(queryFilters(c, reservedParams))
+	if err := yelp.SortReviews(b.Reviews, sortKey); err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+
+	resp := &reviewListResponse{
+		BusinessID: b.ID,
+		Total:      len(b.Reviews),
+		Page:       page,
+		PerPage:    perPage,
+		Reviews:    []yelp.Review{},
+	}
+	// Pages past the last are empty; checked first so that large pages do
+	// not overflow.
+	if pages := (len(b.Reviews) + perPage - 1) / perPage; page-1 < pages {
+		start := (page - 1) * perPage
+		end := start + perPage
+		if end > len(b.Reviews) {
+			end = len(b.Reviews)
+		}
+		resp.Reviews = b.Reviews[start:end]
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func businessRatingHandle(c echo.Context) error {
+	b, err := storedBusiness(c)
+	if err != nil {
+		return err
+	}
+
+	resp := &ratingResponse{
+		BusinessID: b.ID,
+		Model:      c.QueryParam("model"),
+		Filters:    queryFilters(c, reservedParams),
+	}
+	b.FilterReviews(resp.Filters)
+	if resp.Rating, err = b.CalculateRatingWith(resp.Model); err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+	resp.ReviewCount = len(b.Reviews)
+	return c.JSON(http.StatusOK, resp)
+}
+
+// authorHandle returns an author and the stored reviews they wrote. The
+// profile is served from the stored reviews or the profile cache, and never
+// fetched.
+func authorHandle(c echo.Context) error {
+	id := c.Param("id")
+	index, err := store.ReviewIndex()
+	if err != nil {
+		return err
+	}
+
+	resp := &authorResponse{Reviews: []authorReview{}}
+	result := index.Search(yelp.ReviewQuery{AuthorID: id, Limit: index.Len() + 1})
+	for _, h := range result.Hits {
+		resp.Reviews = append(resp.Reviews, authorReview{
+			BusinessID:   h.BusinessID,
+			BusinessName: h.BusinessName,
+			Review:       h.Review,
+		})
+		if resp.ID == "" || h.Review.Author.HasProfile() {
+			resp.Author = h.Review.Author
+		}
+	}
+
+	if !resp.HasProfile() {
+		if profile, ok := yelp.CachedAuthorProfile(id); ok {
+			resp.Author.ID = id
+			resp.Profile = profile
+		}
+	}
+	if resp.ID == "" {
+		return newError(http.StatusNotFound, fmt.Errorf("author %s not found", id))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+// reviewQuery parses the query parameters of a review search.
+func reviewQuery(c echo.Context) (q yelp.ReviewQuery, err error) {
+	q.Text = c.QueryParam("q")
+	q.BusinessID = c.QueryParam("business")
+	q.AuthorID = c.QueryParam("author")
+
+	for _, p := range []struct {
+		name string
+		dst  *float64
+	}{
+		{"min_rating", &q.MinRating},
+		{"max_rating", &q.MaxRating},
+	} {
+		if v := c.QueryParam(p.name); v != "" {
+			if *p.dst, err = strconv.ParseFloat(v, 64); err != nil {
+				return q, fmt.Errorf("invalid %s %q", p.name, v)
+			}
+		}
+	}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"since", &q.Since},
+		{"until", &q.Until},
+	} {
+		if v := c.QueryParam(p.name); v != "" {
+			if *p.dst, err = time.Parse("2006-01-02", v); err != nil {
+				return q, fmt.Errorf("invalid %s %q, expected YYYY-MM-DD", p.name, v)
+			}
+		}
+	}
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{
+		{"limit", &q.Limit},
+		{"offset", &q.Offset},
+	} {
+		if v := c.QueryParam(p.name); v != "" {
+			if *p.dst, err = strconv.Atoi(v); err != nil || *p.dst < 0 {
+				return q, fmt.Errorf("invalid %s %q", p.name, v)
+			}
+		}
+	}
+	return q, nil
+}
+
+func reviewSearchHandle(c echo.Context) (err error) {
+	q, err := reviewQuery(c)
+	if err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+	if q.BusinessID != "" {
+		// Accept business URLs as well as IDs.
+		if b, err := store.FindBusiness(q.BusinessID); err == nil {
+			q.BusinessID = b.ID
+		}
+	}
+
+	index, err := store.ReviewIndex()
+	if err != nil {
+		return newError(http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, index.Search(q))
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+var scheduler *yelp.Scheduler
+
+type scheduleRequest struct {
+	URL      string `json:"url"`
+	Schedule string `json:"schedule"`
+	Group    string `json:"group"`
+}
+
+type scheduleResponse struct {
+	Groups     map[string]string      `json:"groups,omitempty"`
+	Businesses []yelp.TrackedBusiness `json:"businesses,omitempty"`
+}
+
+// schedulerRequired rejects requests when the scheduler is disabled.
+func schedulerRequired(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if scheduler == nil {
+			return newError(http.StatusServiceUnavailable, fmt.Errorf("scheduler disabled, set SCHEDULER=1 to enable"))
+		}
+		return next(c)
+	}
+}
+
+func scheduleStatusHandle(c echo.Context) error {
+	return c.JSON(http.StatusOK, &scheduleResponse{
+		Groups:     scheduler.Groups(),
+		Businesses: scheduler.Status(),
+	})
+}
+
+func scheduleTrackHandle(c echo.Context) (err error) {
+	decoder := json.NewDecoder(c.Request().Body())
+	request := &scheduleRequest{}
+
+	if err = decoder.Decode(request); err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+	t, err := scheduler.Track(request.URL, request.Schedule, request.Group)
+	if err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusOK, &scheduleResponse{
+		Businesses: []yelp.TrackedBusiness{t},
+	})
+}
+
+func scheduleUntrackHandle(c echo.Context) error {
+	scheduler.Untrack(c.QueryParam("url"))
+	return c.NoContent(http.StatusNoContent)
+}
+
+func scheduleGroupHandle(c echo.Context) (err error) {
+	decoder := json.NewDecoder(c.Request().Body())
+	request := &scheduleRequest{}
+
+	if err = decoder.Decode(request); err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+	if err = scheduler.SetGroupSchedule(request.Group, request.Schedule); err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusOK, &scheduleResponse{
+		Groups: scheduler.Groups(),
+	})
+}
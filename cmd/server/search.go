@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+type searchResponse struct {
+	Businesses []yelp.LocalBusiness `json:"businesses"`
+}
+
+func searchHandle(c echo.Context) (err error) {
+	resp := &searchResponse{}
+
+	location := c.QueryParam("location")
+	if location == "" {
+		return newError(http.StatusBadRequest, fmt.Errorf("location is required"))
+	}
+
+	pages, err := strconv.Atoi(c.QueryParam("pages"))
+	if err != nil || pages <= 0 {
+		pages = 3
+	}
+
+	resp.Businesses, err = yelp.Search(c.QueryParam("term"), location, pages)
+	if err != nil {
+		return newError(http.StatusBadGateway, err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
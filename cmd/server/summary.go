@@ -0,0 +1,33 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/labstack/echo"
+
+	"github.com/Taik/yelp-reviews/yelp"
+)
+
+type summaryRequest struct {
+	// Business is the ID or URL of a stored business.
+	Business string              `json:"business"`
+	Filters  []yelp.ReviewFilter `json:"filters"`
+	yelp.SummaryOptions
+}
+
+func summaryHandle(c echo.Context) (err error) {
+	decoder := json.NewDecoder(c.Request().Body())
+	request := &summaryRequest{}
+	if err = decoder.Decode(request); err != nil {
+		return newError(http.StatusBadRequest, err)
+	}
+
+	b, err := store.FindBusiness(request.Business)
+	if err != nil {
+		return newError(http.StatusNotFound, err)
+	}
+
+	b.FilterReviews(request.Filters)
+	return c.JSON(http.StatusOK, b.Summarize(request.SummaryOptions))
+}
@@ -0,0 +1,301 @@
+package yelp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Alert defines a notable change detected on a tracked business.
+type Alert struct {
+	Time         time.Time    `json:"time"`
+	BusinessID   string       `json:"business_id"`
+	BusinessName string       `json:"business_name"`
+	URL          string       `json:"url"`
+	Kind         string       `json:"kind"`
+	Message      string       `json:"message"`
+	ChangePoint  *ChangePoint `json:"change_point,omitempty"`
+}
+
+// AlertSink delivers alerts.
+type AlertSink interface {
+	Emit(a Alert) error
+}
+
+// LogSink writes alerts to the standard logger.
+type LogSink struct{}
+
+// Emit implements AlertSink.
+func (LogSink) Emit(a Alert) error {
+	log.Printf("alert %s for %s: %s\n", a.Kind, a.BusinessName, a.Message)
+	return nil
+}
+
+// FileSink appends alerts to a file as JSON lines.
+type FileSink struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// Emit implements AlertSink.
+func (s *FileSink) Emit(a Alert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(a)
+}
+
+// WebhookSink posts alerts as JSON to an HTTP endpoint.
+type WebhookSink struct {
+	URL string
+}
+
+// Emit implements AlertSink.
+func (s WebhookSink) Emit(a Alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	r, err := client.Post(s.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	r.Body.Close()
+	if r.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned %s", s.URL, r.Status)
+	}
+	return nil
+}
+
+// AlertRule defines the alerting thresholds of a business.
+type AlertRule struct {
+	BusinessID string `json:"business_id"`
+	ChangePointOptions
+	// MaxRatingDrop alerts when the rating drops by more than this between refreshes. Zero disables.
+	MaxRatingDrop float64 `json:"max_rating_drop"`
+	// MinRating alerts when the rating falls below this. Zero disables.
+	MinRating float64 `json:"min_rating"`
+}
+
+// initialAlertDays is the number of days of past change points alerted the
+// first time a business is evaluated. Older ones are recorded as alerted.
+const initialAlertDays = 30
+
+// Alerter evaluates businesses after each refresh and emits alerts to its sinks.
+//
+// Rules and the change points already alerted are persisted in the store;
+// businesses without a rule use DefaultRule.
+type Alerter struct {
+	DefaultRule AlertRule
+
+	path  string
+	store *Store
+	sinks []AlertSink
+	mu    sync.Mutex
+	rules map[string]AlertRule
+	// emitted holds the keys of the change points alerted per business.
+	emitted map[string]map[string]bool
+}
+
+// alerterState defines the persisted state of an alerter.
+type alerterState struct {
+	Rules   []AlertRule         `json:"rules"`
+	Emitted map[string][]string `json:"emitted"`
+}
+
+// OpenAlerter opens the alerter whose rules are persisted in the store.
+func OpenAlerter(store *Store, sinks ...AlertSink) (*Alerter, error) {
+	a := &Alerter{
+		path:    filepath.Join(store.Dir(), "alerts.json"),
+		store:   store,
+		sinks:   sinks,
+		rules:   map[string]AlertRule{},
+		emitted: map[string]map[string]bool{},
+	}
+
+	var raw json.RawMessage
+	if err := readJSON(a.path, &raw); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var state alerterState
+	if len(raw) > 0 {
+		// Earlier versions persisted the rules only, as a list.
+		if raw[0] == '[' {
+			if err := json.Unmarshal(raw, &state.Rules); err != nil {
+				return nil, err
+			}
+		} else if err := json.Unmarshal(raw, &state); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range state.Rules {
+		a.rules[r.BusinessID] = r
+	}
+	for id, keys := range state.Emitted {
+		a.emitted[id] = toSet(keys)
+	}
+	return a, nil
+}
+
+// save persists the rules and alerted change points. The caller must hold a.mu.
+func (a *Alerter) save() error {
+	state := alerterState{
+		Rules:   a.sortedRules(),
+		Emitted: make(map[string][]string, len(a.emitted)),
+	}
+	for id, keys := range a.emitted {
+		state.Emitted[id] = sortedKeys(keys)
+	}
+	return writeJSON(a.path, state)
+}
+
+// changePointKey identifies a shift across evaluations.
+func changePointKey(cp ChangePoint) string {
+	return cp.Metric + "/" + cp.Direction + "/" + cp.Date.Format("2006-01-02")
+}
+
+// newChangePoints returns the change points of the business not alerted yet
+// and records them as alerted. The first time a business is evaluated, change
+// points detected more than initialAlertDays before now are recorded without
+// being returned.
+func (a *Alerter) newChangePoints(id string, points []ChangePoint, now time.Time) (fresh []ChangePoint) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	emitted, known := a.emitted[id]
+	if !known {
+		emitted = map[string]bool{}
+		a.emitted[id] = emitted
+	}
+	cutoff := now.AddDate(0, 0, -initialAlertDays)
+
+	changed := !known
+	for _, cp := range points {
+		key := changePointKey(cp)
+		if emitted[key] {
+			continue
+		}
+		emitted[key], changed = true, true
+		if known || !cp.Detected.Before(cutoff) {
+			fresh = append(fresh, cp)
+		}
+	}
+	if changed {
+		if err := a.save(); err != nil {
+			log.Printf("failed to save alerts %s: %v\n", a.path, err)
+		}
+	}
+	return fresh
+}
+
+// SetRule sets the alerting thresholds of a business.
+func (a *Alerter) SetRule(r AlertRule) error {
+	if r.BusinessID == "" {
+		return fmt.Errorf("alert rule has no business id")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.rules[r.BusinessID] = r
+	return a.save()
+}
+
+// Rules returns the alerting thresholds of every business with a rule.
+func (a *Alerter) Rules() []AlertRule {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.sortedRules()
+}
+
+// sortedRules returns the rules by business ID. The caller must hold a.mu.
+func (a *Alerter) sortedRules() []AlertRule {
+	rules := make([]AlertRule, 0, len(a.rules))
+	for _, r := range a.rules {
+		rules = append(rules, r)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].BusinessID < rules[j].BusinessID
+	})
+	return rules
+}
+
+func (a *Alerter) rule(id string) AlertRule {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if r, ok := a.rules[id]; ok {
+		return r
+	}
+	r := a.DefaultRule
+	r.BusinessID = id
+	return r
+}
+
+// Evaluate checks a freshly refreshed business against its rule, emits the
+// resulting alerts to every sink and returns them.
+//
+// Each change point is alerted once: change points already alerted for the
+// business, identified by metric, direction and start date, are skipped.
+func (a *Alerter) Evaluate(b *LocalBusiness, snap Snapshot) (alerts []Alert) {
+	rule := a.rule(b.ID)
+
+	var prev *Snapshot
+	if snapshots, err := a.store.Snapshots(b.ID); err == nil {
+		for i := len(snapshots) - 1; i >= 0; i-- {
+			if snapshots[i].Time.Before(snap.Time) {
+				prev = &snapshots[i]
+				break
+			}
+		}
+	}
+
+	newAlert := func(kind, format string, args ...interface{}) Alert {
+		return Alert{
+			Time:         snap.Time,
+			BusinessID:   b.ID,
+			BusinessName: b.Name,
+			URL:          b.URL,
+			Kind:         kind,
+			Message:      fmt.Sprintf(format, args...),
+		}
+	}
+
+	for _, cp := range a.newChangePoints(b.ID, b.DetectChangePoints(rule.ChangePointOptions), snap.Time) {
+		cp := cp
+		alert := newAlert("change_point", "%s shifted %s from %.2f to %.2f on %s",
+			cp.Metric, cp.Direction, cp.Before, cp.After, cp.Date.Format("2006-01-02"))
+		alert.ChangePoint = &cp
+		alerts = append(alerts, alert)
+	}
+
+	if rule.MaxRatingDrop > 0 && prev != nil && prev.Rating-snap.Rating > rule.MaxRatingDrop {
+		alerts = append(alerts, newAlert("rating_drop", "rating dropped from %.2f to %.2f", prev.Rating, snap.Rating))
+	}
+	if rule.MinRating > 0 && snap.Rating < rule.MinRating {
+		alerts = append(alerts, newAlert("min_rating", "rating %.2f is below %.2f", snap.Rating, rule.MinRating))
+	}
+
+	for _, alert := range alerts {
+		for _, sink := range a.sinks {
+			if err := sink.Emit(alert); err != nil {
+				log.Printf("failed to emit alert for %s: %v\n", b.Name, err)
+			}
+		}
+	}
+	return alerts
+}
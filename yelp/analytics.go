@@ -0,0 +1,190 @@
+package yelp
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// RatingStats defines the rating summary of a population of reviews.
+type RatingStats struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	// Distribution holds review counts indexed by star rating minus one.
+	Distribution []int `json:"distribution"`
+}
+
+func (s *RatingStats) add(r *Review) {
+	if s.Distribution == nil {
+		s.Distribution = make([]int, 5)
+	}
+	if star := int(r.Rating); star >= 1 && star <= 5 {
+		s.Distribution[star-1]++
+	}
+	s.Mean = (s.Mean*float64(s.Count) + r.Rating) / float64(s.Count+1)
+	s.Count++
+}
+
+// RatingByLanguage splits the rating summary by review language.
+//
+// Reviews of unknown language are grouped under "unknown".
+func (b *LocalBusiness) RatingByLanguage() map[string]RatingStats {
+	return groupRatings(b.Reviews, func(r *Review) string {
+		if r.Language == "" {
+			return "unknown"
+		}
+		return r.Language
+	})
+}
+
+// Reviewer origins relative to the business.
+const (
+	OriginSameCity    = "same_city"
+	OriginSameRegion  = "same_region"
+	OriginOutOfRegion = "out_of_region"
+	OriginUnknown     = "unknown"
+)
+
+// splitLocation splits a location such as "Astoria, NY" into city and region.
+func splitLocation(location string) (city, region string) {
+	location = strings.TrimSpace(location)
+	i := strings.LastIndex(location, ",")
+	if i < 0 {
+		return location, ""
+	}
+	return strings.TrimSpace(location[:i]), strings.TrimSpace(location[i+1:])
+}
+
+// ReviewerOrigin returns where the author of the review lives relative to the
+// business: OriginSameCity, OriginSameRegion, OriginOutOfRegion or
+// OriginUnknown when either location is missing.
+func (b *LocalBusiness) ReviewerOrigin(r *Review) string {
+	city, region := splitLocation(r.Author.Location)
+	if region == "" || b.Address.Region == "" {
+		return OriginUnknown
+	}
+	switch {
+	case !strings.EqualFold(region, strings.TrimSpace(b.Address.Region)):
+		return OriginOutOfRegion
+	case strings.EqualFold(city, strings.TrimSpace(b.Address.Locality)):
+		return OriginSameCity
+	}
+	return OriginSameRegion
+}
+
+// RatingByOrigin splits the rating summary by reviewer origin.
+func (b *LocalBusiness) RatingByOrigin() map[string]RatingStats {
+	stats := groupRatings(b.Reviews, b.ReviewerOrigin)
+	for _, origin := range []string{OriginSameCity, OriginSameRegion, OriginOutOfRegion, OriginUnknown} {
+		if _, ok := stats[origin]; !ok {
+			stats[origin] = RatingStats{Distribution: make([]int, 5)}
+		}
+	}
+	return stats
+}
+
+// groupRatings summarizes ratings per group key.
+func groupRatings(reviews []Review, key func(r *Review) string) map[string]RatingStats {
+	groups := map[string]RatingStats{}
+	for i := range reviews {
+		k := key(&reviews[i])
+		s := groups[k]
+		s.add(&reviews[i])
+		groups[k] = s
+	}
+	return groups
+}
+
+// RecommendationStats compares recommended and "not currently recommended" reviews.
+type RecommendationStats struct {
+	Recommended    RatingStats `json:"recommended"`
+	NotRecommended RatingStats `json:"not_recommended"`
+}
+
+// CompareRecommendation splits the rating summary by recommendation status.
+func (b *LocalBusiness) CompareRecommendation() (stats RecommendationStats) {
+	for i := range b.Reviews {
+		if b.Reviews[i].NotRecommended {
+			stats.NotRecommended.add(&b.Reviews[i])
+		} else {
+			stats.Recommended.add(&b.Reviews[i])
+		}
+	}
+	return stats
+}
+
+// ResponseStats defines how a business owner responds to reviews.
+type ResponseStats struct {
+	Responses          int           `json:"responses"`
+	ResponseRate       float64       `json:"response_rate"`
+	MedianResponseTime time.Duration `json:"median_response_time"`
+}
+
+// OwnerResponseStats returns the owner response rate and median response time.
+func (b *LocalBusiness) OwnerResponseStats() (stats ResponseStats) {
+	var delays []time.Duration
+	for _, r := range b.Reviews {
+		if !r.HasOwnerResponse() {
+			continue
+		}
+		stats.Responses++
+		if !r.Date.IsZero() && !r.OwnerResponse.Date.IsZero() {
+			delays = append(delays, r.OwnerResponse.Date.Sub(r.Date))
+		}
+	}
+
+	if len(b.Reviews) > 0 {
+		stats.ResponseRate = float64(stats.Responses) / float64(len(b.Reviews))
+	}
+	stats.MedianResponseTime = medianDuration(delays)
+	return stats
+}
+
+func medianDuration(d []time.Duration) time.Duration {
+	if len(d) == 0 {
+		return 0
+	}
+	sort.Slice(d, func(i, j int) bool { return d[i] < d[j] })
+	if len(d)%2 == 0 {
+		return (d[len(d)/2-1] + d[len(d)/2]) / 2
+	}
+	return d[len(d)/2]
+}
+
+// UpdateStats defines how updated reviews changed their ratings.
+type UpdateStats struct {
+	Updated    int     `json:"updated"`
+	Upgrades   int     `json:"upgrades"`
+	Downgrades int     `json:"downgrades"`
+	MeanDelta  float64 `json:"mean_delta"`
+}
+
+// TrendsDownward returns whether updated reviews lowered their ratings on average.
+func (s UpdateStats) TrendsDownward() bool {
+	return s.MeanDelta < 0
+}
+
+// ReviewUpdateStats compares updated reviews against their previous versions.
+func (b *LocalBusiness) ReviewUpdateStats() (stats UpdateStats) {
+	var sum float64
+	for _, r := range b.Reviews {
+		prev, ok := r.PreviousVersion()
+		if !ok || prev.Rating == 0 {
+			continue
+		}
+		delta := r.Rating - prev.Rating
+		switch {
+		case delta > 0:
+			stats.Upgrades++
+		case delta < 0:
+			stats.Downgrades++
+		}
+		stats.Updated++
+		sum += delta
+	}
+
+	if stats.Updated > 0 {
+		stats.MeanDelta = sum / float64(stats.Updated)
+	}
+	return stats
+}
@@ -0,0 +1,251 @@
+package yelp
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// neutralSentiment is the sentiment magnitude below which a mention counts as
+// neither positive nor negative.
+const neutralSentiment = 0.05
+
+var sentenceRe = regexp.MustCompile(`[^.!?;\n]+`)
+
+// Aspect defines a facet of a business detected in review text by keywords.
+// Keywords may span several words, such as "wait time".
+type Aspect struct {
+	Name     string   `json:"name"`
+	Keywords []string `json:"keywords"`
+}
+
+// DefaultAspects defines the aspects detected when none are configured.
+var DefaultAspects = []Aspect{
+	{"food", []string{
+		"food", "dish", "dishes", "meal", "meals", "taste", "tasted", "flavor", "flavors",
+		"flavour", "portion", "portions", "menu", "sandwich", "sandwiches", "pizza",
+		"burger", "dessert", "breakfast", "lunch", "dinner", "coffee", "drinks",
+	}},
+	{"service", []string{
+		"service", "staff", "waiter", "waiters", "waitress", "server", "servers",
+		"bartender", "host", "hostess", "manager", "owner", "employee", "employees",
+		"cashier",
+	}},
+	{"price", []string{
+		"price", "prices", "priced", "cost", "costs", "expensive", "cheap", "overpriced",
+		"value", "bill", "affordable", "money", "pricey",
+	}},
+	{"cleanliness", []string{
+		"clean", "cleanliness", "dirty", "filthy", "spotless", "bathroom", "bathrooms",
+		"restroom", "restrooms", "hygiene", "sticky", "smell",
+	}},
+	{"wait_time", []string{
+		"wait", "waited", "waiting", "wait time", "line", "queue", "slow", "quick",
+		"fast", "minutes", "hour", "took forever", "reservation",
+	}},
+}
+
+// AspectMention defines a sentence of a review mentioning an aspect.
+type AspectMention struct {
+	Aspect    string  `json:"aspect"`
+	Keyword   string  `json:"keyword"`
+	Sentence  string  `json:"sentence"`
+	Sentiment float64 `json:"sentiment"`
+}
+
+// AspectMentions returns the sentences of the review text that mention each
+// aspect, scored with the sentiment of the sentence. A sentence mentions an
+// aspect at most once.
+func (r *Review) AspectMentions(aspects []Aspect) (mentions []AspectMention) {
+	for _, sentence := range sentenceRe.FindAllString(r.Description, -1) {
+		words := tokenize(sentence)
+		if len(words) == 0 {
+			continue
+		}
+		sentence = strings.TrimSpace(sentence)
+
+		sentiment, scored := 0.0, false
+		for _, a := range aspects {
+			keyword := matchKeyword(words, a.Keywords)
+			if keyword == "" {
+				continue
+			}
+			if !scored {
+				sentiment, scored = SentimentScore(sentence), true
+			}
+			mentions = append(mentions, AspectMention{
+				Aspect:    a.Name,
+				Keyword:   keyword,
+				Sentence:  sentence,
+				Sentiment: sentiment,
+			})
+		}
+	}
+	return mentions
+}
+
+// matchKeyword returns the first keyword occurring in words.
+func matchKeyword(words []string, keywords []string) string {
+	for _, k := range keywords {
+		if containsPhrase(words, tokenize(k)) {
+			return k
+		}
+	}
+	return ""
+}
+
+// containsPhrase reports whether phrase occurs as consecutive words.
+func containsPhrase(words, phrase []string) bool {
+	if len(phrase) == 0 {
+		return false
+	}
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		match := true
+		for j, w := range phrase {
+			if words[i+j] != w {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// AspectScore defines the sentiment of the mentions of an aspect.
+type AspectScore struct {
+	Aspect    string  `json:"aspect"`
+	Mentions  int     `json:"mentions"`
+	Positive  int     `json:"positive"`
+	Negative  int     `json:"negative"`
+	Sentiment float64 `json:"sentiment"`
+	// Rating is the mean star rating of the reviews mentioning the aspect.
+	Rating  float64 `json:"rating"`
+	reviews int
+}
+
+func (s *AspectScore) add(m AspectMention) {
+	s.Sentiment = (s.Sentiment*float64(s.Mentions) + m.Sentiment) / float64(s.Mentions+1)
+	s.Mentions++
+	switch {
+	case m.Sentiment >= neutralSentiment:
+		s.Positive++
+	case m.Sentiment <= -neutralSentiment:
+		s.Negative++
+	}
+}
+
+func (s *AspectScore) addReview(r *Review) {
+	s.Rating = (s.Rating*float64(s.reviews) + r.Rating) / float64(s.reviews+1)
+	s.reviews++
+}
+
+// AspectPeriod defines the aspect scores of the reviews of a period.
+type AspectPeriod struct {
+	Start   time.Time     `json:"start"`
+	Reviews int           `json:"reviews"`
+	Aspects []AspectScore `json:"aspects"`
+}
+
+// AspectReport defines the aspect scores of a business, overall and per period.
+type AspectReport struct {
+	Aspects []AspectScore  `json:"aspects"`
+	Periods []AspectPeriod `json:"periods"`
+}
+
+// periodStart returns the start of the "day", "week" (starting monday),
+// "month" or "year" containing t.
+func periodStart(t time.Time, period string) (time.Time, error) {
+	switch period {
+	case "day":
+		return truncateDay(t), nil
+	case "week":
+		offset := (int(t.Weekday()) + 6) % 7
+		return truncateDay(t).AddDate(0, 0, -offset), nil
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()), nil
+	case "year":
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location()), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid period %q", period)
+}
+
+// aspectScores accumulates the scores of each aspect by name.
+type aspectScores map[string]*AspectScore
+
+func (s aspectScores) add(r *Review, mentions []AspectMention) {
+	seen := map[string]bool{}
+	for _, m := range mentions {
+		score, ok := s[m.Aspect]
+		if !ok {
+			score = &AspectScore{Aspect: m.Aspect}
+			s[m.Aspect] = score
+		}
+		score.add(m)
+		if !seen[m.Aspect] {
+			score.addReview(r)
+			seen[m.Aspect] = true
+		}
+	}
+}
+
+func (s aspectScores) list(aspects []Aspect) []AspectScore {
+	scores := make([]AspectScore, 0, len(aspects))
+	for _, a := range aspects {
+		if score, ok := s[a.Name]; ok {
+			scores = append(scores, *score)
+		} else {
+			scores = append(scores, AspectScore{Aspect: a.Name})
+		}
+	}
+	return scores
+}
+
+// AspectReport scores each aspect across the reviews of the business, and
+// across the dated reviews of each period ("day", "week", "month" or "year").
+//
+// DefaultAspects is used when aspects is empty.
+func (b *LocalBusiness) AspectReport(aspects []Aspect, period string) (report AspectReport, err error) {
+	if len(aspects) == 0 {
+		aspects = DefaultAspects
+	}
+	if _, err := periodStart(time.Time{}, period); err != nil {
+		return report, err
+	}
+
+	overall := aspectScores{}
+	periods := map[time.Time]aspectScores{}
+	counts := map[time.Time]int{}
+	for i := range b.Reviews {
+		r := &b.Reviews[i]
+		mentions := r.AspectMentions(aspects)
+		overall.add(r, mentions)
+		if r.Date.IsZero() {
+			continue
+		}
+
+		start, _ := periodStart(r.Date, period)
+		if periods[start] == nil {
+			periods[start] = aspectScores{}
+		}
+		periods[start].add(r, mentions)
+		counts[start]++
+	}
+
+	report.Aspects = overall.list(aspects)
+	for start, scores := range periods {
+		report.Periods = append(report.Periods, AspectPeriod{
+			Start:   start,
+			Reviews: counts[start],
+			Aspects: scores.list(aspects),
+		})
+	}
+	sort.Slice(report.Periods, func(i, j int) bool {
+		return report.Periods[i].Start.Before(report.Periods[j].Start)
+	})
+	return report, nil
+}
@@ -0,0 +1,156 @@
+package yelp
+
+import (
+	"bytes"
+	"log"
+	"math"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/cathalgarvey/sqrape"
+	"github.com/hashicorp/golang-lru"
+)
+
+const (
+	baseURL = "https://www.yelp.com"
+
+	// maxProfileFetches bounds concurrent user page fetches per enrichment.
+	maxProfileFetches = 8
+)
+
+var (
+	profileCache *lru.ARCCache
+
+	profileMu    sync.Mutex
+	profileCalls = map[string]*profileCall{}
+)
+
+func init() {
+	profileCache, _ = lru.NewARC(4096)
+}
+
+// profileCall deduplicates concurrent fetches of the same user page.
+type profileCall struct {
+	wg      sync.WaitGroup
+	profile AuthorProfile
+	err     error
+}
+
+func userURL(id string) string {
+	return baseURL + "/user_details?userid=" + url.QueryEscape(id)
+}
+
+// CachedAuthorProfile returns the cached profile of the author with the given
+// ID, without fetching it.
+func CachedAuthorProfile(id string) (AuthorProfile, bool) {
+	if val, ok := profileCache.Get(id); ok {
+		return val.(AuthorProfile), true
+	}
+	return AuthorProfile{}, false
+}
+
+// FetchAuthorProfile returns the profile of the author with the given ID.
+//
+// Profiles are cached, and concurrent calls for the same author share one fetch.
+func FetchAuthorProfile(id string) (AuthorProfile, error) {
+	if val, ok := profileCache.Get(id); ok {
+		return val.(AuthorProfile), nil
+	}
+
+	profileMu.Lock()
+	if call, ok := profileCalls[id]; ok {
+		profileMu.Unlock()
+		call.wg.Wait()
+		return call.profile, call.err
+	}
+	call := &profileCall{}
+	call.wg.Add(1)
+	profileCalls[id] = call
+	profileMu.Unlock()
+
+	call.profile, call.err = fetchAuthorProfile(id)
+	if call.err == nil {
+		profileCache.Add(id, call.profile)
+	}
+	call.wg.Done()
+
+	profileMu.Lock()
+	delete(profileCalls, id)
+	profileMu.Unlock()
+	return call.profile, call.err
+}
+
+func fetchAuthorProfile(id string) (p AuthorProfile, err error) {
+	log.Printf("fetching author profile %s\n", id)
+	body, err := getPage(userURL(id))
+	if err != nil {
+		return p, err
+	}
+
+	err = sqrape.ExtractHTMLReader(bytes.NewReader(body), &p)
+	p.FetchedAt = time.Now()
+	return p, err
+}
+
+// EnrichAuthors fetches the user page of each distinct review author once and
+// attaches the resulting profile to their reviews.
+func (b *LocalBusiness) EnrichAuthors() {
+	ids := map[string]bool{}
+	for _, r := range b.Reviews {
+		if r.Author.ID != "" {
+			ids[r.Author.ID] = true
+		}
+	}
+
+	profiles := make(map[string]AuthorProfile, len(ids))
+	mu := &sync.Mutex{}
+	wg := &sync.WaitGroup{}
+	sem := make(chan struct{}, maxProfileFetches)
+
+	for id := range ids {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			p, err := FetchAuthorProfile(id)
+			if err != nil {
+				log.Printf("failed to fetch author profile %s: %v\n", id, err)
+				return
+			}
+
+			mu.Lock()
+			profiles[id] = p
+			mu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+
+	for i := range b.Reviews {
+		if p, ok := profiles[b.Reviews[i].Author.ID]; ok {
+			b.Reviews[i].Author.Profile = p
+		}
+	}
+	log.Printf("enriched %d authors for %s\n", len(profiles), b.Name)
+}
+
+// Credibility returns a score between 0 and 1 combining the author's review
+// count, friend count and elite status. Authors whose profile was not fetched
+// are scored as not elite, so that scores stay comparable.
+func (a *Author) Credibility() float64 {
+	score := 0.5*saturate(a.ReviewCount, 100) + 0.3*saturate(a.FriendCount, 200)
+	if a.HasProfile() {
+		score += 0.2 * math.Min(float64(len(a.Profile.EliteYears))/3, 1)
+	}
+	return score
+}
+
+// saturate scales n logarithmically so that limit and above map to 1.
+func saturate(n, limit int) float64 {
+	if n <= 0 {
+		return 0
+	}
+	return math.Min(math.Log1p(float64(n))/math.Log1p(float64(limit)), 1)
+}
@@ -0,0 +1,199 @@
+package yelp
+
+import (
+	"math"
+	"time"
+)
+
+// Default CUSUM parameters, in standard deviations of the series.
+const (
+	defaultCUSUMThreshold = 4.0
+	defaultCUSUMDrift     = 0.5
+)
+
+// ChangePoint defines a significant shift in a daily review series.
+type ChangePoint struct {
+	// Date is the start of the shift, and Detected the day it became
+	// significant, usually some days later.
+	Date     time.Time `json:"date"`
+	Detected time.Time `json:"detected"`
+	// Metric is either "rating" (daily mean rating) or "volume" (daily review count).
+	Metric    string  `json:"metric"`
+	Direction string  `json:"direction"`
+	Before    float64 `json:"before"`
+	After     float64 `json:"after"`
+}
+
+// ChangePointOptions defines the sensitivity of change-point detection.
+type ChangePointOptions struct {
+	// Threshold is the cumulative deviation, in standard deviations, that signals a shift.
+	Threshold float64 `json:"threshold"`
+	// Drift is the per-sample deviation, in standard deviations, tolerated as noise.
+	Drift float64 `json:"drift"`
+}
+
+func (o ChangePointOptions) withDefaults() ChangePointOptions {
+	if o.Threshold <= 0 {
+		o.Threshold = defaultCUSUMThreshold
+	}
+	if o.Drift <= 0 {
+		o.Drift = defaultCUSUMDrift
+	}
+	return o
+}
+
+// dailySeries returns, for each day between the first and last dated review,
+// the number of reviews and their mean rating (NaN on days without reviews).
+func dailySeries(reviews []Review) (days []time.Time, counts, means []float64) {
+	dated := datedReviews(reviews)
+	if len(dated) == 0 {
+		return nil, nil, nil
+	}
+
+	last := truncateDay(dated[len(dated)-1].Date)
+	i := 0
+	for day := truncateDay(dated[0].Date); !day.After(last); day = day.AddDate(0, 0, 1) {
+		var n, sum float64
+		for ; i < len(dated) && truncateDay(dated[i].Date).Equal(day); i++ {
+			n++
+			sum += dated[i].Rating
+		}
+		days = append(days, day)
+		counts = append(counts, n)
+		means = append(means, sum/n)
+	}
+	return days, counts, means
+}
+
+// cusum runs a two-sided CUSUM over xs, skipping NaN samples, and returns the
+// index where each detected shift starts, the index where it was detected and
+// its direction (+1 or -1).
+//
+// The reference level is learned from the first samples and re-learned after
+// each shift. The noise level is estimated from successive differences, which
+// is robust to the shifts being detected.
+func cusum(xs []float64, opts ChangePointOptions) (indices, detected, directions []int) {
+	var vals []float64
+	var at []int
+	for i, x := range xs {
+		if !math.IsNaN(x) {
+			vals = append(vals, x)
+			at = append(at, i)
+		}
+	}
+	if len(vals) < 4 {
+		return nil, nil, nil
+	}
+
+	var diff float64
+	for i := 1; i < len(vals); i++ {
+		diff += math.Abs(vals[i] - vals[i-1])
+	}
+	std := diff / float64(len(vals)-1) / 1.128
+	if std == 0 {
+		return nil, nil, nil
+	}
+
+	baseline := len(vals) / 4
+	if baseline > 30 {
+		baseline = 30
+	}
+	if baseline < 2 {
+		baseline = 2
+	}
+	reference := func(from int) float64 {
+		to := from + baseline
+		if to > len(vals) {
+			to = len(vals)
+		}
+		return segmentMean(vals[from:to])
+	}
+
+	k, h := opts.Drift*std, opts.Threshold*std
+	mean := reference(0)
+	var hi, lo float64
+	hiStart, loStart := 0, 0
+	for i := 0; i < len(vals); i++ {
+		if hi == 0 {
+			hiStart = i
+		}
+		if lo == 0 {
+			loStart = i
+		}
+		hi = math.Max(0, hi+vals[i]-mean-k)
+		lo = math.Max(0, lo+mean-vals[i]-k)
+
+		start, direction := 0, 0
+		switch {
+		case hi > h:
+			start, direction = hiStart, 1
+		case lo > h:
+			start, direction = loStart, -1
+		default:
+			continue
+		}
+
+		indices, directions = append(indices, at[start]), append(directions, direction)
+		detected = append(detected, at[i])
+		mean = reference(start)
+		hi, lo = 0, 0
+		if next := start + baseline - 1; next > i {
+			i = next
+		}
+	}
+	return indices, detected, directions
+}
+
+// segmentMean returns the mean of the non-NaN samples of xs.
+func segmentMean(xs []float64) float64 {
+	var n, sum float64
+	for _, x := range xs {
+		if !math.IsNaN(x) {
+			n++
+			sum += x
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / n
+}
+
+// changePoints converts CUSUM detections over a daily series into ChangePoints.
+func changePoints(metric string, days []time.Time, xs []float64, opts ChangePointOptions) (points []ChangePoint) {
+	indices, detected, directions := cusum(xs, opts)
+	for i, idx := range indices {
+		start, end := 0, len(xs)
+		if i > 0 {
+			start = indices[i-1]
+		}
+		if i+1 < len(indices) {
+			end = indices[i+1]
+		}
+
+		direction := "up"
+		if directions[i] < 0 {
+			direction = "down"
+		}
+		points = append(points, ChangePoint{
+			Date:      days[idx],
+			Detected:  days[detected[i]],
+			Metric:    metric,
+			Direction: direction,
+			Before:    segmentMean(xs[start:idx]),
+			After:     segmentMean(xs[idx:end]),
+		})
+	}
+	return points
+}
+
+// DetectChangePoints detects significant shifts in the daily mean rating and
+// daily review volume of the business using a two-sided CUSUM.
+func (b *LocalBusiness) DetectChangePoints(opts ChangePointOptions) []ChangePoint {
+	opts = opts.withDefaults()
+	days, counts, means := dailySeries(b.Reviews)
+	return append(
+		changePoints("rating", days, means, opts),
+		changePoints("volume", days, counts, opts)...,
+	)
+}
@@ -0,0 +1,176 @@
+package yelp
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// recentVelocityDays is the trailing window of the recent review velocity.
+const recentVelocityDays = 90
+
+// BusinessComparison defines the benchmark figures of a business.
+type BusinessComparison struct {
+	ID              string  `json:"id"`
+	Name            string  `json:"name"`
+	URL             string  `json:"url"`
+	AggregateRating float64 `json:"aggregate_rating"`
+	// Rating is the rating of the filtered reviews under the report model.
+	Rating      float64 `json:"rating"`
+	ReviewCount int     `json:"review_count"`
+	// Distribution holds review counts indexed by star rating minus one.
+	Distribution    []int   `json:"distribution"`
+	ReviewsPerMonth float64 `json:"reviews_per_month"`
+	// RecentReviewsPerMonth is the review velocity over the last 90 days.
+	RecentReviewsPerMonth float64 `json:"recent_reviews_per_month"`
+	// LocalShare is the share of reviews written by reviewers from the
+	// locality of the business.
+	LocalShare float64 `json:"local_share"`
+	Sentiment  float64 `json:"sentiment"`
+}
+
+// ComparisonReport defines businesses compared side by side under the same
+// review filters and rating model.
+type ComparisonReport struct {
+	GeneratedAt time.Time            `json:"generated_at"`
+	Filters     []ReviewFilter       `json:"filters"`
+	Model       string               `json:"model"`
+	Businesses  []BusinessComparison `json:"businesses"`
+}
+
+// reviewVelocity returns the reviews per month since the first dated review,
+// and over the trailing window of days ending at now.
+func reviewVelocity(reviews []Review, now time.Time, windowDays int) (overall, recent float64) {
+	dated := datedReviews(reviews)
+	if len(dated) == 0 {
+		return 0, 0
+	}
+
+	const daysPerMonth = 365.25 / 12
+	days := now.Sub(dated[0].Date).Hours() / 24
+	if days < 1 {
+		days = 1
+	}
+	overall = float64(len(dated)) / days * daysPerMonth
+
+	cutoff := now.AddDate(0, 0, -windowDays)
+	var n int
+	for _, r := range dated {
+		if r.Date.After(cutoff) {
+			n++
+		}
+	}
+	recent = float64(n) / float64(windowDays) * daysPerMonth
+	return overall, recent
+}
+
+// Compare benchmarks businesses against each other. Each business is filtered
+// with filters and rated with model (the default model if empty); the
+// businesses themselves are left unchanged.
+func Compare(businesses []LocalBusiness, filters []ReviewFilter, model string) (report ComparisonReport, err error) {
+	report = ComparisonReport{
+		GeneratedAt: time.Now(),
+		Filters:     filters,
+		Model:       model,
+	}
+	for _, b := range businesses {
+		b.FilterReviews(filters)
+		rating, err := b.CalculateRatingWith(model)
+		if err != nil {
+			return report, err
+		}
+
+		c := BusinessComparison{
+			ID:              b.ID,
+			Name:            b.Name,
+			URL:             b.URL,
+			AggregateRating: b.AggregateRating,
+			Rating:          rating,
+			ReviewCount:     len(b.Reviews),
+			Distribution:    make([]int, 5),
+			Sentiment:       b.SentimentStats().Mean,
+		}
+		var local int
+		for i := range b.Reviews {
+			if star := int(b.Reviews[i].Rating); star >= 1 && star <= 5 {
+				c.Distribution[star-1]++
+			}
+			if b.ReviewerOrigin(&b.Reviews[i]) == OriginSameCity {
+				local++
+			}
+		}
+		if len(b.Reviews) > 0 {
+			c.LocalShare = float64(local) / float64(len(b.Reviews))
+		}
+		c.ReviewsPerMonth, c.RecentReviewsPerMonth = reviewVelocity(b.Reviews, report.GeneratedAt, recentVelocityDays)
+		report.Businesses = append(report.Businesses, c)
+	}
+	return report, nil
+}
+
+// FetchBusinesses fetches the businesses and their reviews concurrently, in
+// the order of urls. Fetches are bounded by the global fetch limits.
+func FetchBusinesses(urls []string, opts FetchOptions) ([]LocalBusiness, error) {
+	businesses := make([]LocalBusiness, len(urls))
+	errs := make([]error, len(urls))
+	wg := &sync.WaitGroup{}
+	for i, url := range urls {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			b, err := NewBusiness(url)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %v", url, err)
+				return
+			}
+			if err := b.FetchReviewsWithOptions(opts); err != nil {
+				errs[i] = fmt.Errorf("%s: %v", url, err)
+				return
+			}
+			businesses[i] = b
+		}(i, url)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return businesses, nil
+}
+
+// WriteComparisonCSV writes the compared businesses as CSV with a header row.
+func WriteComparisonCSV(w io.Writer, report ComparisonReport) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"id", "name", "url", "aggregate_rating", "rating", "review_count",
+		"stars_1", "stars_2", "stars_3", "stars_4", "stars_5",
+		"reviews_per_month", "recent_reviews_per_month", "local_share", "sentiment",
+	})
+	for _, c := range report.Businesses {
+		row := []string{
+			c.ID,
+			c.Name,
+			c.URL,
+			strconv.FormatFloat(c.AggregateRating, 'f', 1, 64),
+			strconv.FormatFloat(c.Rating, 'f', 4, 64),
+			strconv.Itoa(c.ReviewCount),
+		}
+		for _, n := range c.Distribution {
+			row = append(row, strconv.Itoa(n))
+		}
+		row = append(row,
+			strconv.FormatFloat(c.ReviewsPerMonth, 'f', 2, 64),
+			strconv.FormatFloat(c.RecentReviewsPerMonth, 'f', 2, 64),
+			strconv.FormatFloat(c.LocalShare, 'f', 4, 64),
+			strconv.FormatFloat(c.Sentiment, 'f', 4, 64),
+		)
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,91 @@
+package yelp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	specs := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"foo * * * *",
+		"* * * * mon-",
+		"* * * jan-foo *",
+		"@fortnightly",
+	}
+	for _, spec := range specs {
+		if _, err := parseCron(spec); err == nil {
+			t.Errorf("parseCron(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		spec, from, want string
+	}{
+		// Steps.
+		{"*/15 * * * *", "2026-01-10 10:15", "2026-01-10 10:30"},
+		{"*/15 * * * *", "2026-01-10 10:16", "2026-01-10 10:30"},
+		{"*/15 * * * *", "2026-01-31 23:50", "2026-02-01 00:00"},
+		{"10/20 * * * *", "2026-01-10 10:31", "2026-01-10 10:50"},
+		// Month ends.
+		{"0 0 1 * *", "2026-01-31 12:00", "2026-02-01 00:00"},
+		{"0 0 31 * *", "2026-02-01 00:00", "2026-03-31 00:00"},
+		{"0 12 29 2 *", "2026-03-01 00:00", "2028-02-29 12:00"},
+		{"@monthly", "2026-12-15 08:00", "2027-01-01 00:00"},
+		// Weekday ranges and names; 2026-01-02 is a friday.
+		{"0 9 * * mon-fri", "2026-01-02 09:00", "2026-01-05 09:00"},
+		{"0 9 * * MON-FRI", "2026-01-05 08:59", "2026-01-05 09:00"},
+		{"0 9 * jan-mar sat", "2026-03-29 00:00", "2027-01-02 09:00"},
+		// Day 7 is sunday.
+		{"0 9 * * 7", "2026-01-01 10:00", "2026-01-04 09:00"},
+		{"0 9 * * 5-7", "2026-01-03 10:00", "2026-01-04 09:00"},
+		{"0 0 * * sun", "2026-02-28 00:00", "2026-03-01 00:00"},
+		// Day of month and day of week match either when both are set;
+		// 2026-01-13 is a tuesday.
+		{"0 0 13 * fri", "2026-01-01 00:00", "2026-01-02 00:00"},
+		{"0 0 13 * fri", "2026-01-12 00:00", "2026-01-13 00:00"},
+		{"0 0 13 * *", "2026-01-01 00:00", "2026-01-13 00:00"},
+		{"0 0 * * fri", "2026-01-12 00:00", "2026-01-16 00:00"},
+	}
+	for _, tt := range tests {
+		s, err := parseCron(tt.spec)
+		if err != nil {
+			t.Errorf("parseCron(%q) error: %v", tt.spec, err)
+			continue
+		}
+		if got := s.Next(date(tt.from)); !got.Equal(date(tt.want)) {
+			t.Errorf("%q Next(%s) = %s, want %s", tt.spec, tt.from, got.Format("2006-01-02 15:04"), tt.want)
+		}
+	}
+}
+
+func TestCronNextNone(t *testing.T) {
+	s, err := parseCron("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); !got.IsZero() {
+		t.Errorf("Next = %s, want zero time", got)
+	}
+}
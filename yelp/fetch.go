@@ -0,0 +1,87 @@
+package yelp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	fetchSlotsMu sync.RWMutex
+	fetchSlots   chan struct{}
+	fetchRate    = &rateLimiter{}
+)
+
+// SetMaxConcurrentFetches limits the number of page fetches in flight across
+// all businesses. Zero removes the limit. Fetches already waiting for a slot
+// keep the previous limit.
+func SetMaxConcurrentFetches(n int) {
+	fetchSlotsMu.Lock()
+	defer fetchSlotsMu.Unlock()
+
+	if n <= 0 {
+		fetchSlots = nil
+		return
+	}
+	fetchSlots = make(chan struct{}, n)
+}
+
+// SetRateLimit limits page fetches across all businesses to perSecond
+// requests per second. Zero removes the limit.
+func SetRateLimit(perSecond float64) {
+	fetchRate.mu.Lock()
+	defer fetchRate.mu.Unlock()
+
+	fetchRate.interval = 0
+	if perSecond > 0 {
+		fetchRate.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+}
+
+// rateLimiter spaces out events by a fixed interval.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// wait blocks until the next event is allowed.
+func (l *rateLimiter) wait() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.interval == 0 {
+		return
+	}
+
+	now := time.Now()
+	if l.next.After(now) {
+		time.Sleep(l.next.Sub(now))
+		now = l.next
+	}
+	l.next = now.Add(l.interval)
+}
+
+// getPage fetches the body of a page within the global fetch limits. The body
+// is read in full before the fetch slot is released. Responses other than 2xx
+// are returned as errors.
+func getPage(url string) ([]byte, error) {
+	fetchSlotsMu.RLock()
+	slots := fetchSlots
+	fetchSlotsMu.RUnlock()
+	if slots != nil {
+		slots <- struct{}{}
+		defer func() { <-slots }()
+	}
+	fetchRate.wait()
+	r, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode < 200 || r.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s returned %s", url, r.Status)
+	}
+	return ioutil.ReadAll(r.Body)
+}
@@ -0,0 +1,303 @@
+package yelp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GraphOptions defines the thresholds of a reviewer graph.
+type GraphOptions struct {
+	// MinShared is the number of shared reviewers for businesses to be
+	// connected. Defaults to 1.
+	MinShared int `json:"min_shared"`
+	// MinCoReviews is the number of businesses two authors must have both
+	// reviewed to be clustered together. Defaults to 2.
+	MinCoReviews int `json:"min_co_reviews"`
+}
+
+func (o GraphOptions) withDefaults() GraphOptions {
+	if o.MinShared <= 0 {
+		o.MinShared = 1
+	}
+	if o.MinCoReviews <= 0 {
+		o.MinCoReviews = 2
+	}
+	return o
+}
+
+// GraphNode defines a business of a reviewer graph.
+type GraphNode struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Reviewers int    `json:"reviewers"`
+}
+
+// GraphEdge defines the reviewers shared by two businesses.
+type GraphEdge struct {
+	Source          string   `json:"source"`
+	Target          string   `json:"target"`
+	SharedReviewers int      `json:"shared_reviewers"`
+	Jaccard         float64  `json:"jaccard"`
+	Authors         []string `json:"authors"`
+}
+
+// AuthorCluster defines authors who repeatedly reviewed the same businesses.
+type AuthorCluster struct {
+	Authors    []string `json:"authors"`
+	Businesses []string `json:"businesses"`
+}
+
+// ReviewerGraph defines the overlap of reviewers between businesses.
+type ReviewerGraph struct {
+	Nodes    []GraphNode     `json:"nodes"`
+	Edges    []GraphEdge     `json:"edges"`
+	Clusters []AuthorCluster `json:"clusters"`
+}
+
+// reviewerSet returns the IDs of the authors who reviewed the business.
+func reviewerSet(b *LocalBusiness) map[string]bool {
+	authors := map[string]bool{}
+	for _, r := range b.Reviews {
+		if r.Author.ID != "" {
+			authors[r.Author.ID] = true
+		}
+	}
+	return authors
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// BuildReviewerGraph connects businesses sharing reviewers and clusters the
+// authors who co-reviewed several of the same businesses.
+func BuildReviewerGraph(businesses []LocalBusiness, opts GraphOptions) (g ReviewerGraph) {
+	opts = opts.withDefaults()
+
+	reviewers := make([]map[string]bool, len(businesses))
+	reviewed := map[string][]int{}
+	for i := range businesses {
+		reviewers[i] = reviewerSet(&businesses[i])
+		for a := range reviewers[i] {
+			reviewed[a] = append(reviewed[a], i)
+		}
+		g.Nodes = append(g.Nodes, GraphNode{
+			ID:        businesses[i].ID,
+			Name:      businesses[i].Name,
+			Reviewers: len(reviewers[i]),
+		})
+	}
+
+	// Only authors of several businesses connect businesses together.
+	shared := map[[2]int][]string{}
+	for a, idx := range reviewed {
+		for x := 0; x < len(idx); x++ {
+			for y := x + 1; y < len(idx); y++ {
+				pair := [2]int{idx[x], idx[y]}
+				shared[pair] = append(shared[pair], a)
+			}
+		}
+	}
+	for pair, authors := range shared {
+		if len(authors) < opts.MinShared {
+			continue
+		}
+		sort.Strings(authors)
+		union := len(reviewers[pair[0]]) + len(reviewers[pair[1]]) - len(authors)
+		g.Edges = append(g.Edges, GraphEdge{
+			Source:          businesses[pair[0]].ID,
+			Target:          businesses[pair[1]].ID,
+			SharedReviewers: len(authors),
+			Jaccard:         float64(len(authors)) / float64(union),
+			Authors:         authors,
+		})
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].SharedReviewers != g.Edges[j].SharedReviewers {
+			return g.Edges[i].SharedReviewers > g.Edges[j].SharedReviewers
+		}
+		return g.Edges[i].Source+g.Edges[i].Target < g.Edges[j].Source+g.Edges[j].Target
+	})
+
+	g.Clusters = authorClusters(businesses, reviewed, opts.MinCoReviews)
+	return g
+}
+
+// authorClusters groups authors connected by having co-reviewed at least
+// minCoReviews businesses, using union-find.
+func authorClusters(businesses []LocalBusiness, reviewed map[string][]int, minCoReviews int) []AuthorCluster {
+	parent := map[string]string{}
+	var find func(a string) string
+	find = func(a string) string {
+		if parent[a] != a {
+			parent[a] = find(parent[a])
+		}
+		return parent[a]
+	}
+
+	// Count co-reviews among authors of several businesses only.
+	perBusiness := make([][]string, len(businesses))
+	for a, idx := range reviewed {
+		if len(idx) < minCoReviews {
+			continue
+		}
+		parent[a] = a
+		for _, i := range idx {
+			perBusiness[i] = append(perBusiness[i], a)
+		}
+	}
+	coReviews := map[[2]string]int{}
+	for _, authors := range perBusiness {
+		sort.Strings(authors)
+		for x := 0; x < len(authors); x++ {
+			for y := x + 1; y < len(authors); y++ {
+				pair := [2]string{authors[x], authors[y]}
+				coReviews[pair]++
+				if coReviews[pair] == minCoReviews {
+					parent[find(pair[0])] = find(pair[1])
+				}
+			}
+		}
+	}
+
+	members := map[string][]string{}
+	for a := range parent {
+		root := find(a)
+		members[root] = append(members[root], a)
+	}
+
+	clusters := []AuthorCluster{}
+	for _, authors := range members {
+		if len(authors) < 2 {
+			continue
+		}
+		sort.Strings(authors)
+		ids := map[string]bool{}
+		for _, a := range authors {
+			for _, i := range reviewed[a] {
+				ids[businesses[i].ID] = true
+			}
+		}
+		clusters = append(clusters, AuthorCluster{
+			Authors:    authors,
+			Businesses: sortedKeys(ids),
+		})
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if len(clusters[i].Authors) != len(clusters[j].Authors) {
+			return len(clusters[i].Authors) > len(clusters[j].Authors)
+		}
+		return clusters[i].Authors[0] < clusters[j].Authors[0]
+	})
+	return clusters
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteGraphML writes the business graph as GraphML.
+func (g *ReviewerGraph) WriteGraphML(w io.Writer) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "name", For: "node", Name: "name", Type: "string"},
+			{ID: "reviewers", For: "node", Name: "reviewers", Type: "int"},
+			{ID: "shared", For: "edge", Name: "shared_reviewers", Type: "int"},
+			{ID: "jaccard", For: "edge", Name: "jaccard", Type: "double"},
+		},
+		Graph: graphMLGraph{EdgeDefault: "undirected"},
+	}
+	for _, n := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: n.ID,
+			Data: []graphMLData{
+				{Key: "name", Value: n.Name},
+				{Key: "reviewers", Value: strconv.Itoa(n.Reviewers)},
+			},
+		})
+	}
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: e.Source,
+			Target: e.Target,
+			Data: []graphMLData{
+				{Key: "shared", Value: strconv.Itoa(e.SharedReviewers)},
+				{Key: "jaccard", Value: strconv.FormatFloat(e.Jaccard, 'f', 4, 64)},
+			},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteDOT writes the business graph in the Graphviz DOT language.
+func (g *ReviewerGraph) WriteDOT(w io.Writer) error {
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("graph reviewers {\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&buf, "  %s [label=%s, reviewers=%d];\n", quote(n.ID), quote(n.Name), n.Reviewers)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&buf, "  %s -- %s [weight=%d, jaccard=%.4f, label=%d];\n",
+			quote(e.Source), quote(e.Target), e.SharedReviewers, e.Jaccard, e.SharedReviewers)
+	}
+	buf.WriteString("}\n")
+	_, err := buf.WriteTo(w)
+	return err
+}
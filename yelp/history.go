@@ -0,0 +1,91 @@
+package yelp
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// RatingPoint defines the rating of a business as of the end of a day.
+type RatingPoint struct {
+	Date             time.Time `json:"date"`
+	Reviews          int       `json:"reviews"`
+	CumulativeCount  int       `json:"cumulative_count"`
+	CumulativeRating float64   `json:"cumulative_rating"`
+	RollingCount     int       `json:"rolling_count"`
+	RollingRating    float64   `json:"rolling_rating"`
+}
+
+// RatingHistory reconstructs the cumulative rating and the rating over the
+// trailing window of days as of each day that received reviews.
+//
+// Reviews without a date are ignored. Apply FilterReviews beforehand to get
+// the history of the filtered rating.
+func (b *LocalBusiness) RatingHistory(windowDays int) (points []RatingPoint) {
+	reviews := datedReviews(b.Reviews)
+
+	var sum, windowSum float64
+	windowStart := 0
+	for i := 0; i < len(reviews); {
+		day := truncateDay(reviews[i].Date)
+		p := RatingPoint{Date: day}
+		for ; i < len(reviews) && truncateDay(reviews[i].Date).Equal(day); i++ {
+			sum += reviews[i].Rating
+			windowSum += reviews[i].Rating
+			p.Reviews++
+		}
+
+		cutoff := day.AddDate(0, 0, -windowDays)
+		for windowDays > 0 && !reviews[windowStart].Date.After(cutoff) {
+			windowSum -= reviews[windowStart].Rating
+			windowStart++
+		}
+
+		p.CumulativeCount = i
+		p.CumulativeRating = sum / float64(i)
+		p.RollingCount = i - windowStart
+		if p.RollingCount > 0 {
+			p.RollingRating = windowSum / float64(p.RollingCount)
+		}
+		points = append(points, p)
+	}
+	return points
+}
+
+// datedReviews returns the reviews with a date, oldest first.
+func datedReviews(reviews []Review) []Review {
+	dated := make([]Review, 0, len(reviews))
+	for _, r := range reviews {
+		if !r.Date.IsZero() {
+			dated = append(dated, r)
+		}
+	}
+	sort.SliceStable(dated, func(i, j int) bool {
+		return dated[i].Date.Before(dated[j].Date)
+	})
+	return dated
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// WriteRatingHistoryCSV writes rating points as CSV with a header row.
+func WriteRatingHistoryCSV(w io.Writer, points []RatingPoint) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"date", "reviews", "cumulative_count", "cumulative_rating", "rolling_count", "rolling_rating"})
+	for _, p := range points {
+		cw.Write([]string{
+			p.Date.Format("2006-01-02"),
+			strconv.Itoa(p.Reviews),
+			strconv.Itoa(p.CumulativeCount),
+			strconv.FormatFloat(p.CumulativeRating, 'f', 4, 64),
+			strconv.Itoa(p.RollingCount),
+			strconv.FormatFloat(p.RollingRating, 'f', 4, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
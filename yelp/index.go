@@ -0,0 +1,251 @@
+package yelp
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BM25 ranking parameters.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+var phraseRe = regexp.MustCompile(`"[^"]*"|[^\s"]+`)
+
+// indexedReview defines a review along with the business it belongs to.
+type indexedReview struct {
+	businessID   string
+	businessName string
+	review       Review
+	length       int
+	positions    map[string][]int
+}
+
+// ReviewIndex is an in-memory inverted index over review text.
+type ReviewIndex struct {
+	docs      []indexedReview
+	postings  map[string][]int
+	avgLength float64
+}
+
+// NewReviewIndex indexes the reviews of the businesses.
+func NewReviewIndex(businesses []LocalBusiness) *ReviewIndex {
+	idx := &ReviewIndex{postings: map[string][]int{}}
+	var total int
+	for i := range businesses {
+		for _, r := range businesses[i].Reviews {
+			doc := len(idx.docs)
+			words := tokenize(r.Description)
+			positions := map[string][]int{}
+			for pos, w := range words {
+				if positions[w] == nil {
+					idx.postings[w] = append(idx.postings[w], doc)
+				}
+				positions[w] = append(positions[w], pos)
+			}
+
+			idx.docs = append(idx.docs, indexedReview{
+				businessID:   businesses[i].ID,
+				businessName: businesses[i].Name,
+				review:       r,
+				length:       len(words),
+				positions:    positions,
+			})
+			total += len(words)
+		}
+	}
+	if len(idx.docs) > 0 {
+		idx.avgLength = float64(total) / float64(len(idx.docs))
+	}
+	return idx
+}
+
+// Len returns the number of indexed reviews.
+func (idx *ReviewIndex) Len() int {
+	return len(idx.docs)
+}
+
+// ReviewQuery defines a search over indexed reviews.
+type ReviewQuery struct {
+	// Text holds the words every review must contain. Double quoted phrases
+	// must occur verbatim, e.g. `pastrami "rye bread"`.
+	Text       string
+	BusinessID string
+	AuthorID   string
+	MinRating  float64
+	// MaxRating is ignored when zero.
+	MaxRating float64
+	// Since and Until bound the review date, and are ignored when zero.
+	Since time.Time
+	Until time.Time
+	// Limit defaults to 20. A negative Offset is treated as zero.
+	Limit  int
+	Offset int
+}
+
+// ReviewHit defines a review matching a query.
+type ReviewHit struct {
+	BusinessID   string  `json:"business_id"`
+	BusinessName string  `json:"business_name"`
+	Score        float64 `json:"score"`
+	Review       Review  `json:"review"`
+}
+
+// ReviewSearchResult defines a page of reviews matching a query.
+type ReviewSearchResult struct {
+	Total int         `json:"total"`
+	Hits  []ReviewHit `json:"hits"`
+}
+
+// parseQuery splits query text into phrases of one or more words.
+func parseQuery(text string) (phrases [][]string) {
+	for _, part := range phraseRe.FindAllString(text, -1) {
+		if words := tokenize(strings.Trim(part, `"`)); len(words) > 0 {
+			phrases = append(phrases, words)
+		}
+	}
+	return phrases
+}
+
+// matchesQuery reports whether the review satisfies the filters of the query.
+func matchesQuery(d *indexedReview, q ReviewQuery) bool {
+	r := &d.review
+	switch {
+	case q.BusinessID != "" && d.businessID != q.BusinessID,
+		q.AuthorID != "" && r.Author.ID != q.AuthorID,
+		q.MinRating > 0 && r.Rating < q.MinRating,
+		q.MaxRating > 0 && r.Rating > q.MaxRating,
+		!q.Since.IsZero() && r.Date.Before(q.Since),
+		!q.Until.IsZero() && r.Date.After(q.Until):
+		return false
+	}
+	return true
+}
+
+// phraseMatches returns, for each review containing the phrase, the number of
+// occurrences of the phrase.
+func (idx *ReviewIndex) phraseMatches(phrase []string) map[int]int {
+	matches := map[int]int{}
+	for _, doc := range idx.postings[phrase[0]] {
+		positions := idx.docs[doc].positions
+		for _, start := range positions[phrase[0]] {
+			if phraseAt(positions, phrase, start) {
+				matches[doc]++
+			}
+		}
+	}
+	return matches
+}
+
+// phraseAt reports whether the phrase occurs at position start of a review
+// with the given word positions.
+func phraseAt(positions map[string][]int, phrase []string, start int) bool {
+	for i, w := range phrase[1:] {
+		found := false
+		for _, pos := range positions[w] {
+			if pos == start+i+1 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Search returns the reviews containing every word and phrase of the query
+// and matching its filters, ranked by BM25 relevance. Without query text,
+// matching reviews are returned newest first.
+func (idx *ReviewIndex) Search(q ReviewQuery) (result ReviewSearchResult) {
+	if q.Limit <= 0 {
+		q.Limit = 20
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+
+	var candidates map[int]float64
+	for _, phrase := range parseQuery(q.Text) {
+		matches := idx.phraseMatches(phrase)
+		idf := math.Log(1 + (float64(len(idx.docs))-float64(len(matches))+0.5)/(float64(len(matches))+0.5))
+
+		scores := map[int]float64{}
+		for doc, tf := range matches {
+			if candidates != nil {
+				if _, ok := candidates[doc]; !ok {
+					continue
+				}
+			}
+			norm := 1 - bm25B + bm25B*float64(idx.docs[doc].length)/idx.avgLength
+			scores[doc] = candidates[doc] + idf*float64(tf)*(bm25K1+1)/(float64(tf)+bm25K1*norm)
+		}
+		candidates = scores
+	}
+
+	var hits []ReviewHit
+	add := func(doc int, score float64) {
+		d := &idx.docs[doc]
+		if matchesQuery(d, q) {
+			hits = append(hits, ReviewHit{
+				BusinessID:   d.businessID,
+				BusinessName: d.businessName,
+				Score:        score,
+				Review:       d.review,
+			})
+		}
+	}
+	if candidates == nil {
+		for doc := range idx.docs {
+			add(doc, 0)
+		}
+	} else {
+		for doc, score := range candidates {
+			add(doc, score)
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Review.Date.After(hits[j].Review.Date)
+	})
+
+	result.Total = len(hits)
+	result.Hits = []ReviewHit{}
+	if q.Offset < len(hits) {
+		end := len(hits)
+		if q.Limit < end-q.Offset {
+			end = q.Offset + q.Limit
+		}
+		result.Hits = hits[q.Offset:end]
+	}
+	return result
+}
+
+// ReviewIndex returns an index over all stored reviews. The index is cached
+// and rebuilt once businesses have been saved through the store.
+func (s *Store) ReviewIndex() (*ReviewIndex, error) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	s.mu.RLock()
+	version := s.version
+	s.mu.RUnlock()
+	if s.index != nil && s.indexVersion == version {
+		return s.index, nil
+	}
+
+	businesses, err := s.Businesses()
+	if err != nil {
+		return nil, err
+	}
+	s.index, s.indexVersion = NewReviewIndex(businesses), version
+	return s.index, nil
+}
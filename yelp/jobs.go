@@ -0,0 +1,421 @@
+package yelp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	neturl "net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Job states.
+const (
+	JobPending  = "pending"
+	JobRunning  = "running"
+	JobDone     = "done"
+	JobFailed   = "failed"
+	JobCanceled = "canceled"
+)
+
+// Job defines a scrape of a single business.
+type Job struct {
+	ID       string       `json:"id"`
+	URL      string       `json:"url"`
+	Options  FetchOptions `json:"options"`
+	Status   string       `json:"status"`
+	Error    string       `json:"error,omitempty"`
+	Attempts int          `json:"attempts"`
+	// PagesDone and PagesTotal report the listing pages fetched so far and
+	// discovered so far while the job is running.
+	PagesDone   int       `json:"pages_done"`
+	PagesTotal  int       `json:"pages_total"`
+	BusinessID  string    `json:"business_id,omitempty"`
+	Rating      float64   `json:"rating"`
+	ReviewCount int       `json:"review_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	cancel chan struct{}
+}
+
+// Finished reports whether the job reached a final state.
+func (j *Job) Finished() bool {
+	return j.Status == JobDone || j.Status == JobFailed || j.Status == JobCanceled
+}
+
+// JobSummary defines the number of jobs per state.
+type JobSummary struct {
+	Total    int `json:"total"`
+	Pending  int `json:"pending"`
+	Running  int `json:"running"`
+	Done     int `json:"done"`
+	Failed   int `json:"failed"`
+	Canceled int `json:"canceled"`
+}
+
+// JobQueue is a persistent queue of business scrape jobs.
+//
+// The queue is saved to disk on every state change. Jobs that were running
+// when the process stopped are requeued when the queue is reopened.
+type JobQueue struct {
+	// Options defines the review listings fetched for each job.
+	Options FetchOptions
+
+	path     string
+	store    *Store
+	mu       sync.Mutex
+	cond     *sync.Cond
+	jobs     map[string]*Job
+	watchers map[string][]chan FetchEvent
+}
+
+// OpenJobQueue opens the queue persisted at path. Scraped businesses are
+// saved to store.
+func OpenJobQueue(path string, store *Store) (*JobQueue, error) {
+	q := &JobQueue{
+		path:     path,
+		store:    store,
+		jobs:     map[string]*Job{},
+		watchers: map[string][]chan FetchEvent{},
+	}
+	q.cond = sync.NewCond(&q.mu)
+
+	var jobs []*Job
+	if err := readJSON(path, &jobs); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, j := range jobs {
+		if j.Status == JobRunning {
+			log.Printf("resuming interrupted job %s for %s\n", j.ID, j.URL)
+			j.Status = JobPending
+		}
+		q.jobs[j.ID] = j
+	}
+	return q, nil
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job id: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// save persists the queue. The caller must hold q.mu.
+func (q *JobQueue) save() {
+	if err := writeJSON(q.path, q.sorted()); err != nil {
+		log.Printf("failed to save job queue %s: %v\n", q.path, err)
+	}
+}
+
+// sorted returns the jobs in creation order. The caller must hold q.mu.
+func (q *JobQueue) sorted() []*Job {
+	jobs := make([]*Job, 0, len(q.jobs))
+	for _, j := range q.jobs {
+		jobs = append(jobs, j)
+	}
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].CreatedAt.Before(jobs[k].CreatedAt)
+	})
+	return jobs
+}
+
+// Enqueue adds a pending job for the business URL with the queue options.
+func (q *JobQueue) Enqueue(url string) (Job, error) {
+	j, _, err := q.Submit(url, q.Options)
+	return j, err
+}
+
+// businessKey identifies the business of a URL regardless of its query.
+func businessKey(rawURL string) string {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return strings.ToLower(u.Host + strings.TrimSuffix(u.Path, "/"))
+}
+
+// Submit adds a pending job for the business URL, unless a job for the same
+// business and listings is already pending or running, in which case that job
+// is returned and created is false.
+func (q *JobQueue) Submit(url string, opts FetchOptions) (j Job, created bool, err error) {
+	if url == "" {
+		return Job{}, false, fmt.Errorf("job has no url")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key := opts.cacheKey(businessKey(url))
+	for _, existing := range q.jobs {
+		if !existing.Finished() && existing.Options.cacheKey(businessKey(existing.URL)) == key {
+			return *existing, false, nil
+		}
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return Job{}, false, err
+	}
+	now := time.Now()
+	opts.Cancel, opts.Progress = nil, nil
+	job := &Job{
+		ID:        id,
+		URL:       url,
+		Options:   opts,
+		Status:    JobPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	q.jobs[job.ID] = job
+	q.save()
+	q.cond.Broadcast()
+	return *job, true, nil
+}
+
+// Cancel cancels a pending or running job. A running job stops after the
+// pages being fetched complete, and keeps no result.
+func (q *JobQueue) Cancel(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, ok := q.jobs[id]
+	switch {
+	case !ok:
+		return Job{}, fmt.Errorf("job %s not found", id)
+	case j.Finished():
+		return *j, fmt.Errorf("job %s already %s", id, j.Status)
+	case j.Status == JobPending:
+		j.Status = JobCanceled
+		j.UpdatedAt = time.Now()
+		q.closeWatchers(id)
+		q.save()
+	case j.cancel != nil:
+		close(j.cancel)
+		j.cancel = nil
+	}
+	return *j, nil
+}
+
+// Job returns the job with the given ID.
+func (q *JobQueue) Job(id string) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, ok := q.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *j, true
+}
+
+// Jobs returns all jobs in creation order.
+func (q *JobQueue) Jobs() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := make([]Job, 0, len(q.jobs))
+	for _, j := range q.sorted() {
+		jobs = append(jobs, *j)
+	}
+	return jobs
+}
+
+// Summary returns the number of jobs per state.
+func (q *JobQueue) Summary() (s JobSummary) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, j := range q.jobs {
+		s.Total++
+		switch j.Status {
+		case JobPending:
+			s.Pending++
+		case JobRunning:
+			s.Running++
+		case JobDone:
+			s.Done++
+		case JobFailed:
+			s.Failed++
+		case JobCanceled:
+			s.Canceled++
+		}
+	}
+	return s
+}
+
+// claim marks the oldest pending job as running. The caller must hold q.mu.
+func (q *JobQueue) claim() *Job {
+	for _, j := range q.sorted() {
+		if j.Status == JobPending {
+			j.Status = JobRunning
+			j.Attempts++
+			j.PagesDone, j.PagesTotal = 0, 0
+			j.cancel = make(chan struct{})
+			j.UpdatedAt = time.Now()
+			q.save()
+			return j
+		}
+	}
+	return nil
+}
+
+func (q *JobQueue) next() *Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.claim()
+}
+
+// wait blocks until a pending job is available and claims it.
+func (q *JobQueue) wait() *Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		if j := q.claim(); j != nil {
+			return j
+		}
+		q.cond.Wait()
+	}
+}
+
+// finish records the outcome of a job.
+func (q *JobQueue) finish(j *Job, b *LocalBusiness, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j.UpdatedAt = time.Now()
+	j.cancel = nil
+	if err == ErrFetchCanceled {
+		j.Status = JobCanceled
+		log.Printf("job %s for %s canceled\n", j.ID, j.URL)
+	} else if err != nil {
+		j.Status = JobFailed
+		j.Error = err.Error()
+		log.Printf("job %s for %s failed: %v\n", j.ID, j.URL, err)
+	} else {
+		j.Status = JobDone
+		j.Error = ""
+		j.BusinessID = b.ID
+		j.Rating = b.CalculateRating()
+		j.ReviewCount = len(b.Reviews)
+	}
+	q.closeWatchers(j.ID)
+	q.save()
+}
+
+// jobEventBuffer is the number of events buffered for each job watcher.
+const jobEventBuffer = 64
+
+// Watch returns a channel receiving the fetch events of the job, closed once
+// the job finishes, and a function to stop watching. Events are dropped for
+// watchers that fall behind. The channel of a finished job is closed
+// immediately.
+func (q *JobQueue) Watch(id string) (<-chan FetchEvent, func(), error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, ok := q.jobs[id]
+	if !ok {
+		return nil, nil, fmt.Errorf("job %s not found", id)
+	}
+	ch := make(chan FetchEvent, jobEventBuffer)
+	if j.Finished() {
+		close(ch)
+		return ch, func() {}, nil
+	}
+	q.watchers[id] = append(q.watchers[id], ch)
+
+	stop := func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		watchers := q.watchers[id]
+		for i, w := range watchers {
+			if w == ch {
+				q.watchers[id] = append(watchers[:i], watchers[i+1:]...)
+				break
+			}
+		}
+	}
+	return ch, stop, nil
+}
+
+// closeWatchers closes the event channels of a finished job. The caller must
+// hold q.mu.
+func (q *JobQueue) closeWatchers(id string) {
+	for _, ch := range q.watchers[id] {
+		close(ch)
+	}
+	delete(q.watchers, id)
+}
+
+// progress records a fetch event of a running job and forwards it to the
+// job watchers.
+func (q *JobQueue) progress(j *Job, e FetchEvent) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j.PagesDone, j.PagesTotal = e.PagesDone, e.PagesTotal
+	j.UpdatedAt = time.Now()
+	for _, ch := range q.watchers[j.ID] {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// process scrapes the business of a claimed job.
+func (q *JobQueue) process(j *Job) {
+	log.Printf("running job %s for %s\n", j.ID, j.URL)
+
+	q.mu.Lock()
+	opts := j.Options
+	opts.Cancel = j.cancel
+	q.mu.Unlock()
+	opts.Progress = func(e FetchEvent) {
+		q.progress(j, e)
+	}
+
+	b, err := NewBusiness(j.URL)
+	if err == nil {
+		err = b.FetchReviewsWithOptions(opts)
+	}
+	if err == nil && q.store != nil {
+		err = q.store.SaveBusiness(&b)
+	}
+	q.finish(j, &b, err)
+}
+
+// Run processes pending jobs with the given number of workers until none are
+// left, and returns the resulting summary.
+func (q *JobQueue) Run(workers int) JobSummary {
+	wg := &sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := q.next(); j != nil; j = q.next() {
+				q.process(j)
+			}
+		}()
+	}
+	wg.Wait()
+	return q.Summary()
+}
+
+// Start processes jobs in the background with the given number of workers,
+// waiting for new jobs as they are enqueued.
+func (q *JobQueue) Start(workers int) {
+	for i := 0; i < workers; i++ {
+		go func() {
+			for {
+				q.process(q.wait())
+			}
+		}()
+	}
+}
@@ -0,0 +1,195 @@
+package yelp
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// stopwords defines common English words excluded from keywords.
+var stopwords = toSet(strings.Fields(`
+	a about above after again against all also am an and any are as at be
+	because been before being below between both but by can could did do does
+	doing down during each even ever every few for from further get got had has
+	have having he her here hers herself him himself his how i if in into is it
+	its itself just me more most my myself no nor not now of off on once only or
+	other our ours ourselves out over own place really same she should so some
+	such than that the their theirs them themselves then there these they this
+	those through to too under until up us very was we were what when where
+	which while who whom why will with would you your yours yourself yourselves
+	i'm i've i'd i'll it's don't didn't wasn't isn't we're we've they're you're
+	can't won't there's that's one two get go went come came back made make
+	definitely always still much many well lot bit ordered order
+`))
+
+func toSet(words []string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+// KeywordOptions defines the terms extracted as keywords.
+type KeywordOptions struct {
+	// NGram is the maximum number of words per term. Defaults to 2.
+	NGram int `json:"ngram"`
+	// Limit is the number of keywords returned per list. Defaults to 20.
+	Limit int `json:"limit"`
+	// MinCount is the minimum number of occurrences of a keyword. Defaults to 2.
+	MinCount int `json:"min_count"`
+}
+
+func (o KeywordOptions) withDefaults() KeywordOptions {
+	if o.NGram <= 0 {
+		o.NGram = 2
+	}
+	if o.Limit <= 0 {
+		o.Limit = 20
+	}
+	if o.MinCount <= 0 {
+		o.MinCount = 2
+	}
+	return o
+}
+
+// Keyword defines a term distinctive of the reviews of a business.
+type Keyword struct {
+	Term  string  `json:"term"`
+	Count int     `json:"count"`
+	Score float64 `json:"score"`
+}
+
+// KeywordReport defines the keywords of all, positive (4 stars or more) and
+// negative (2 stars or less) reviews of a business.
+type KeywordReport struct {
+	All      []Keyword `json:"all"`
+	Positive []Keyword `json:"positive"`
+	Negative []Keyword `json:"negative"`
+}
+
+// terms returns the n-grams of up to n words of text. Terms never start or end
+// with a stopword, and never span sentences.
+func terms(text string, n int) (terms []string) {
+	for _, sentence := range sentenceRe.FindAllString(text, -1) {
+		words := tokenize(sentence)
+		for i := range words {
+			if !isTermWord(words[i]) {
+				continue
+			}
+			for j := i; j < len(words) && j < i+n; j++ {
+				if isTermWord(words[j]) {
+					terms = append(terms, strings.Join(words[i:j+1], " "))
+				}
+			}
+		}
+	}
+	return terms
+}
+
+func isTermWord(w string) bool {
+	if len(w) < 3 || stopwords[w] {
+		return false
+	}
+	for _, r := range w {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Corpus defines the document frequency of terms across businesses. Each
+// business is one document made of the text of all its reviews.
+type Corpus struct {
+	ngram int
+	docs  int
+	ids   map[string]bool
+	df    map[string]int
+}
+
+// NewCorpus builds the background corpus of businesses, counting terms of up
+// to ngram words.
+func NewCorpus(businesses []LocalBusiness, ngram int) *Corpus {
+	c := &Corpus{
+		ngram: ngram,
+		ids:   map[string]bool{},
+		df:    map[string]int{},
+	}
+	for i := range businesses {
+		c.docs++
+		c.ids[businesses[i].ID] = true
+		for t := range termCounts(businesses[i].Reviews, ngram) {
+			c.df[t]++
+		}
+	}
+	return c
+}
+
+func termCounts(reviews []Review, ngram int) map[string]int {
+	counts := map[string]int{}
+	for i := range reviews {
+		for _, t := range terms(reviews[i].Description, ngram) {
+			counts[t]++
+		}
+	}
+	return counts
+}
+
+// Keywords scores the terms of the reviews of the business by TF-IDF against
+// the corpus and returns the most distinctive ones. Options.NGram is capped
+// to the n-gram size of the corpus.
+func (c *Corpus) Keywords(b *LocalBusiness, opts KeywordOptions) (report KeywordReport) {
+	opts = opts.withDefaults()
+	if opts.NGram > c.ngram {
+		opts.NGram = c.ngram
+	}
+
+	var positive, negative []Review
+	for _, r := range b.Reviews {
+		switch {
+		case r.Rating >= 4:
+			positive = append(positive, r)
+		case r.Rating > 0 && r.Rating <= 2:
+			negative = append(negative, r)
+		}
+	}
+
+	report.All = c.score(b.ID, b.Reviews, opts)
+	report.Positive = c.score(b.ID, positive, opts)
+	report.Negative = c.score(b.ID, negative, opts)
+	return report
+}
+
+// score ranks the terms of reviews of the business by TF-IDF.
+func (c *Corpus) score(id string, reviews []Review, opts KeywordOptions) []Keyword {
+	docs, self := c.docs, 0
+	if !c.ids[id] {
+		// The business is not part of the corpus, count it as an extra document.
+		docs, self = docs+1, 1
+	}
+
+	keywords := []Keyword{}
+	for t, count := range termCounts(reviews, opts.NGram) {
+		if count < opts.MinCount {
+			continue
+		}
+		idf := math.Log(float64(docs+1)/float64(c.df[t]+self+1)) + 1
+		keywords = append(keywords, Keyword{
+			Term:  t,
+			Count: count,
+			Score: (1 + math.Log(float64(count))) * idf,
+		})
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].Score != keywords[j].Score {
+			return keywords[i].Score > keywords[j].Score
+		}
+		return keywords[i].Term < keywords[j].Term
+	})
+	if len(keywords) > opts.Limit {
+		keywords = keywords[:opts.Limit]
+	}
+	return keywords
+}
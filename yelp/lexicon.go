@@ -0,0 +1,53 @@
+package yelp
+
+// sentimentLexicon defines the valence of sentiment-bearing words, from -5
+// (very negative) to 5 (very positive), in the style of AFINN.
+var sentimentLexicon = map[string]float64{
+	// Positive.
+	"amazing": 4, "awesome": 4, "best": 3, "better": 2, "brilliant": 4,
+	"clean": 2, "comfortable": 2, "cozy": 2, "courteous": 2, "crispy": 1,
+	"delicious": 3, "delight": 3, "delightful": 3, "divine": 3, "enjoy": 2,
+	"enjoyed": 2, "excellent": 3, "exceptional": 4, "fabulous": 4, "fantastic": 4,
+	"fast": 1, "favorite": 2, "favourite": 2, "fresh": 1, "friendly": 2,
+	"generous": 2, "glad": 2, "good": 2, "gorgeous": 3, "great": 3,
+	"happy": 3, "helpful": 2, "impeccable": 4, "impressed": 3, "impressive": 3,
+	"incredible": 4, "juicy": 2, "kind": 2, "like": 1, "liked": 2,
+	"love": 3, "loved": 3, "lovely": 3, "loves": 3, "nice": 2,
+	"outstanding": 4, "perfect": 3, "perfectly": 3, "pleasant": 2, "pleased": 2,
+	"polite": 2, "prompt": 2, "quick": 1, "reasonable": 1, "recommend": 2,
+	"recommended": 2, "satisfied": 2, "spotless": 3, "superb": 4, "sweet": 2,
+	"tasty": 3, "terrific": 4, "thank": 2, "thanks": 2, "welcoming": 2,
+	"wonderful": 4, "worth": 2, "wow": 4, "yum": 3, "yummy": 3,
+
+	// Negative.
+	"angry": -3, "annoyed": -2, "annoying": -2, "avoid": -3, "awful": -4,
+	"bad": -3, "bland": -2, "broken": -2, "burnt": -2, "careless": -2,
+	"cold": -1, "complain": -2, "complaint": -2, "dirty": -3, "disappointed": -3,
+	"disappointing": -3, "disaster": -4, "disgusting": -4, "dry": -1, "expensive": -1,
+	"filthy": -4, "gross": -3, "greasy": -2, "hate": -3, "hated": -3,
+	"horrible": -4, "ignored": -2, "inedible": -4, "lukewarm": -1, "mediocre": -2,
+	"mess": -2, "mistake": -2, "nasty": -3, "overcooked": -2, "overpriced": -2,
+	"poor": -2, "raw": -1, "refund": -2, "rude": -3, "sad": -2,
+	"sick": -3, "slow": -2, "soggy": -2, "sorry": -1, "stale": -2,
+	"terrible": -4, "unacceptable": -3, "undercooked": -2, "unfriendly": -2, "unhappy": -2,
+	"unprofessional": -3, "upset": -2, "waste": -3, "wasted": -3, "worse": -3,
+	"worst": -4, "wrong": -2,
+}
+
+// sentimentBoosters defines words that intensify (positive) or dampen
+// (negative) the valence of the following word.
+var sentimentBoosters = map[string]float64{
+	"absolutely": 0.293, "completely": 0.293, "especially": 0.293, "extremely": 0.293,
+	"incredibly": 0.293, "really": 0.293, "so": 0.293, "super": 0.293,
+	"too": 0.293, "totally": 0.293, "truly": 0.293, "very": 0.293,
+	"barely": -0.293, "hardly": -0.293, "kinda": -0.293, "little": -0.293,
+	"marginally": -0.293, "slightly": -0.293, "somewhat": -0.293,
+}
+
+// sentimentNegations defines words that invert the valence of the words
+// following them. Contractions ending in "n't" are negations too.
+var sentimentNegations = map[string]bool{
+	"aint": true, "cannot": true, "neither": true, "never": true, "no": true,
+	"none": true, "nor": true, "not": true, "nothing": true, "nowhere": true,
+	"without": true,
+}
@@ -0,0 +1,100 @@
+package yelp
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	localeDateRe   = regexp.MustCompile(`\d{1,4}[./-]\d{1,2}[./-]\d{1,4}`)
+	localeNumberRe = regexp.MustCompile(`\d+([.,]\d+)?`)
+)
+
+// dateLayouts defines the short date layouts used per language, in order of preference.
+var dateLayouts = map[string][]string{
+	"en": {"1/2/2006"},
+	"fr": {"2/1/2006"},
+	"es": {"2/1/2006"},
+	"it": {"2/1/2006"},
+	"pt": {"2/1/2006"},
+	"de": {"2.1.2006"},
+	"nl": {"2-1-2006", "2/1/2006"},
+	"da": {"2.1.2006", "2/1/2006"},
+	"sv": {"2006-01-02"},
+	"fi": {"2.1.2006"},
+	"nb": {"2.1.2006"},
+	"pl": {"2.1.2006"},
+	"tr": {"2.1.2006"},
+	"cs": {"2.1.2006"},
+	"ja": {"2006/1/2"},
+	"zh": {"2006/1/2"},
+}
+
+// decimalCommaLanguages defines languages writing decimals with a comma.
+var decimalCommaLanguages = map[string]bool{
+	"fr": true, "es": true, "it": true, "pt": true, "de": true, "nl": true,
+	"da": true, "sv": true, "fi": true, "nb": true, "pl": true, "tr": true, "cs": true,
+}
+
+// localeLanguage returns the language of a locale such as "fr_FR" or "pt-BR".
+func localeLanguage(locale string) string {
+	lang := strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(lang, "_-"); i >= 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
+
+// parseLocaleDate parses a short date as displayed in the given locale.
+func parseLocaleDate(s, locale string) (time.Time, error) {
+	d := localeDateRe.FindString(s)
+	if d == "" {
+		return time.Time{}, fmt.Errorf("no date in %q", s)
+	}
+	if t, err := time.Parse("2006-01-02", d); err == nil {
+		return t, nil
+	}
+
+	layouts, ok := dateLayouts[localeLanguage(locale)]
+	if !ok {
+		layouts = dateLayouts["en"]
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, d); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid %s date %q", locale, d)
+}
+
+// parseLocaleFloat parses the first number in s as written in the given locale.
+func parseLocaleFloat(s, locale string) (float64, error) {
+	n := localeNumberRe.FindString(s)
+	if decimalCommaLanguages[localeLanguage(locale)] {
+		n = strings.Replace(n, ",", ".", 1)
+	} else {
+		n = strings.Replace(n, ",", "", -1)
+	}
+	return strconv.ParseFloat(n, 64)
+}
+
+// localize tags the review with the language of the listing it was found on
+// and parses fields that were only available in locale-specific formats.
+func (r *Review) localize(locale string) {
+	if r.Language == "" {
+		r.Language = localeLanguage(locale)
+	}
+	if r.Date.IsZero() && r.DateText != "" {
+		if t, err := parseLocaleDate(r.DateText, locale); err == nil {
+			r.Date = t
+		}
+	}
+	if r.Rating == 0 && r.RatingText != "" {
+		if rating, err := parseLocaleFloat(r.RatingText, locale); err == nil {
+			r.Rating = rating
+		}
+	}
+}
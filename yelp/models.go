@@ -2,54 +2,237 @@ package yelp
 
 import (
 	"fmt"
+	"log"
 	"strings"
 	"time"
 )
 
 // Author defines a reviewer.
 type Author struct {
-	ID          string `csss:"a.user-display-name;attr=data-hovercard-id"`
-	Name        string `csss:"a.user-display-name;text"`
-	Location    string `csss:"a.user-location;text"`
-	FriendCount int    `csss:"li.friend-count > b;text"`
-	ReviewCount int    `csss:"li.review-count > b;text"`
+	ID          string        `csss:".user-display-name;attr=data-hovercard-id" json:"id"`
+	Name        string        `csss:".user-display-name;text" json:"name"`
+	Location    string        `csss:"a.user-location;text" json:"location"`
+	FriendCount int           `csss:"li.friend-count > b;text" json:"friend_count"`
+	ReviewCount int           `csss:"li.review-count > b;text" json:"review_count"`
+	Profile     AuthorProfile `json:"profile"`
+}
+
+// AuthorProfile defines the metadata found on a reviewer's user page.
+type AuthorProfile struct {
+	EliteStr        string    `csss:"div.user-details-overview_sidebar li:contains('Elite') p;text" json:"-"`
+	EliteYears      []int     `json:"elite_years"`
+	PhotoCountStr   string    `csss:"li.photo-count strong;text" json:"-"`
+	PhotoCount      int       `json:"photo_count"`
+	YelpingSinceStr string    `csss:"div.user-details-overview_sidebar li:contains('Yelping Since') p;text" json:"-"`
+	YelpingSince    time.Time `json:"yelping_since"`
+	// RatingDistribution holds review counts indexed by star rating minus one.
+	RatingDistribution []int          `json:"rating_distribution"`
+	RatingBuckets      []ratingBucket `csss:"table.histogram tr;obj" json:"-"`
+	FetchedAt          time.Time      `json:"fetched_at"`
+}
+
+type ratingBucket struct {
+	Stars string `csss:"th;text"`
+	Count string `csss:"td.histogram_count;text"`
+}
+
+// XXX: SqrapePostFlight defines custom parsing logic for scraped fields.
+func (p *AuthorProfile) SqrapePostFlight(context ...interface{}) (err error) {
+	p.EliteYears = parseEliteYears(p.EliteStr)
+	p.PhotoCount = parseCount(p.PhotoCountStr)
+
+	p.RatingDistribution = make([]int, 5)
+	for _, b := range p.RatingBuckets {
+		stars := parseCount(b.Stars)
+		if stars >= 1 && stars <= 5 {
+			p.RatingDistribution[stars-1] = parseCount(b.Count)
+		}
+	}
+
+	if s := strings.TrimSpace(p.YelpingSinceStr); s != "" {
+		p.YelpingSince, err = time.Parse("January 2006", s)
+	}
+	return err
+}
+
+// HasProfile returns whether the author was enriched from their user page.
+func (a *Author) HasProfile() bool {
+	return !a.Profile.FetchedAt.IsZero()
+}
+
+// IsElite returns whether the author held elite status in any year.
+func (a *Author) IsElite() bool {
+	return len(a.Profile.EliteYears) > 0
 }
 
 // Address defines a business address.
 type Address struct {
-	StreetAddress string `csss:"span:nth-child(1);text"`
-	Locality      string `csss:"span:nth-child(3);text"`
-	Region        string `csss:"span:nth-child(4);text"`
-	PostalCode    string `csss:"span:nth-child(5);text"`
+	StreetAddress string `csss:"span:nth-child(1);text" json:"street_address"`
+	Locality      string `csss:"span:nth-child(3);text" json:"locality"`
+	Region        string `csss:"span:nth-child(4);text" json:"region"`
+	PostalCode    string `csss:"span:nth-child(5);text" json:"postal_code"`
+}
+
+// OwnerResponse defines the business owner's reply to a review.
+type OwnerResponse struct {
+	Responder string    `csss:"div.biz-owner-reply-header strong;text" json:"responder"`
+	DateStr   string    `csss:"div.biz-owner-reply-header span.bullet-before;text" json:"-"`
+	Date      time.Time `json:"date"`
+	Text      string    `csss:"span.js-content-toggleable;text" json:"text"`
+}
+
+// XXX: SqrapePostFlight defines custom parsing logic for scraped fields.
+func (o *OwnerResponse) SqrapePostFlight(context ...interface{}) (err error) {
+	o.Responder = strings.TrimPrefix(strings.TrimSpace(o.Responder), "Comment from ")
+	o.Text = strings.TrimSpace(o.Text)
+	if o.DateStr = strings.TrimSpace(o.DateStr); o.DateStr != "" {
+		o.Date, err = time.Parse("1/2/2006", o.DateStr)
+	}
+	return err
+}
+
+// Votes defines the useful, funny and cool votes a review received.
+type Votes struct {
+	UsefulStr string `csss:"a.useful span.count;text" json:"-"`
+	Useful    int    `json:"useful"`
+	FunnyStr  string `csss:"a.funny span.count;text" json:"-"`
+	Funny     int    `json:"funny"`
+	CoolStr   string `csss:"a.cool span.count;text" json:"-"`
+	Cool      int    `json:"cool"`
+}
+
+// XXX: SqrapePostFlight defines custom parsing logic for scraped fields.
+func (v *Votes) SqrapePostFlight(context ...interface{}) (err error) {
+	v.Useful = parseCount(v.UsefulStr)
+	v.Funny = parseCount(v.FunnyStr)
+	v.Cool = parseCount(v.CoolStr)
+	return err
+}
+
+// ReviewVersion defines a previous version of an updated review.
+type ReviewVersion struct {
+	RatingStr   string    `csss:"div.i-stars;attr=title" json:"-"`
+	Rating      float64   `json:"rating"`
+	DateStr     string    `csss:"span.rating-qualifier;text" json:"-"`
+	Date        time.Time `json:"date"`
+	Description string    `csss:"p;text" json:"description"`
+}
+
+// XXX: SqrapePostFlight defines custom parsing logic for scraped fields.
+func (v *ReviewVersion) SqrapePostFlight(context ...interface{}) (err error) {
+	v.Rating = parseStarRating(v.RatingStr)
+	v.Description = strings.TrimSpace(v.Description)
+	if d := shortDateRe.FindString(v.DateStr); d != "" {
+		v.Date, err = time.Parse("1/2/2006", d)
+	}
+	return err
 }
 
 // Review defines metadata revolving a review.
 type Review struct {
-	ID          string  `csss:";attr=data-review-id"`
-	Author      Author  `csss:"div.ypassport;obj"`
-	Rating      float64 `csss:"meta[itemprop=ratingValue];attr=content"`
-	DateStr     string  `csss:"meta[itemprop=datePublished];attr=content"`
-	Date        time.Time
-	Description string `csss:"p[itemprop=description];text"`
+	ID             string          `csss:";attr=data-review-id" json:"id"`
+	Author         Author          `csss:"div.ypassport;obj" json:"author"`
+	Rating         float64         `csss:"meta[itemprop=ratingValue];attr=content" json:"rating"`
+	DateStr        string          `csss:"meta[itemprop=datePublished];attr=content" json:"-"`
+	Date           time.Time       `json:"date"`
+	DateText       string          `csss:"div.review-content > div.biz-rating span.rating-qualifier;text" json:"-"`
+	RatingText     string          `csss:"div.review-content > div.biz-rating div.i-stars;attr=title" json:"-"`
+	Description    string          `csss:"p[itemprop=description];text" json:"description"`
+	Language       string          `csss:"p[itemprop=description];attr=lang" json:"language"`
+	OwnerResponse  OwnerResponse   `csss:"div.biz-owner-reply;obj" json:"owner_response"`
+	Votes          Votes           `csss:"ul.voting-buttons;obj" json:"votes"`
+	CheckInStr     string          `csss:"li.review-tags_item:contains('check-in');text" json:"-"`
+	CheckIns       int             `json:"check_ins"`
+	UpdatedStr     string          `csss:"span.rating-qualifier:contains('Updated review');text" json:"-"`
+	Updated        bool            `json:"updated"`
+	History        []ReviewVersion `csss:"div.previous-review;obj" json:"history,omitempty"`
+	NotRecommended bool            `json:"not_recommended"`
 }
 
 // XXX: SqrapePostFlight defines custom parsing logic for scraped fields.
 func (r *Review) SqrapePostFlight(context ...interface{}) (err error) {
+	r.CheckIns = parseCount(r.CheckInStr)
+	r.Language = localeLanguage(r.Language)
+	r.Updated = r.UpdatedStr != "" || len(r.History) > 0
 	if r.DateStr != "" {
 		r.Date, err = time.Parse("2006-01-02", r.DateStr)
 	}
 	return err
 }
 
+// PreviousVersion returns the most recent previous version of an updated review.
+func (r *Review) PreviousVersion() (v ReviewVersion, ok bool) {
+	if len(r.History) == 0 {
+		return v, false
+	}
+	return r.History[0], true
+}
+
+// HasOwnerResponse returns whether the business owner replied to the review.
+func (r *Review) HasOwnerResponse() bool {
+	return r.OwnerResponse.Text != ""
+}
+
+// Interval defines an opening interval in minutes since midnight.
+//
+// Close may exceed 24 hours for intervals ending after midnight.
+type Interval struct {
+	Open  int `json:"open"`
+	Close int `json:"close"`
+}
+
+// OpeningHours defines the opening intervals of a business on a weekday.
+type OpeningHours struct {
+	DayStr    string       `csss:"th;text" json:"-"`
+	HoursStr  string       `csss:"td:nth-of-type(1);text" json:"-"`
+	Weekday   time.Weekday `json:"weekday"`
+	Intervals []Interval   `json:"intervals"`
+}
+
+// XXX: SqrapePostFlight defines custom parsing logic for scraped fields.
+//
+// Rows that cannot be parsed are logged and left without intervals rather than
+// failing the whole business page.
+func (h *OpeningHours) SqrapePostFlight(context ...interface{}) (err error) {
+	h.Weekday, err = parseWeekday(h.DayStr)
+	if err != nil {
+		log.Printf("failed to parse opening hours day %q: %v\n", h.DayStr, err)
+		return nil
+	}
+	h.Intervals, err = parseIntervals(h.HoursStr)
+	if err != nil {
+		log.Printf("failed to parse opening hours %q: %v\n", h.HoursStr, err)
+		h.Intervals = nil
+	}
+	return nil
+}
+
 // LocalBusiness defines a business.
 type LocalBusiness struct {
-	ID              string `csss:"meta[name='yelp-biz-id'];attr=content"`
-	Name            string `csss:"h1.biz-page-title;text"`
-	URL             string
-	Address         Address  `csss:"address;obj"`
-	AggregateRating float64  `csss:"div.biz-rating meta[itemprop=ratingValue];attr=content"`
-	ReviewCount     int      `csss:"div.biz-rating span[itemprop=reviewCount];text"`
-	Reviews         []Review `csss:"div.review;obj"`
+	ID              string         `csss:"meta[name='yelp-biz-id'];attr=content" json:"id"`
+	Name            string         `csss:"h1.biz-page-title;text" json:"name"`
+	URL             string         `json:"url"`
+	Address         Address        `csss:"address;obj" json:"address"`
+	AggregateRating float64        `csss:"div.biz-rating meta[itemprop=ratingValue];attr=content" json:"aggregate_rating"`
+	ReviewCount     int            `csss:"div.biz-rating span[itemprop=reviewCount];text" json:"review_count"`
+	Categories      []string       `csss:"span.category-str-list a;text" json:"categories"`
+	PriceRange      string         `csss:"span.price-range;text" json:"price_range"`
+	PriceLevel      int            `json:"price_level"`
+	Phone           string         `csss:"span.biz-phone;text" json:"phone"`
+	WebsiteStr      string         `csss:"span.biz-website a;attr=href" json:"-"`
+	Website         string         `json:"website"`
+	Hours           []OpeningHours `csss:"table.hours-table tbody tr;obj" json:"hours"`
+	ClaimStatus     string         `csss:"div.claim-status_teaser;text" json:"-"`
+	Claimed         bool           `json:"claimed"`
+	Latitude        float64        `csss:"meta[property='place:location:latitude'];attr=content" json:"latitude"`
+	Longitude       float64        `csss:"meta[property='place:location:longitude'];attr=content" json:"longitude"`
+	Reviews         []Review       `csss:"div.review;obj" json:"reviews,omitempty"`
+	NextURL         string         `csss:"div.pagination-links a.next;attr=href" json:"-"`
+	PageURLs        []string       `csss:"div.pagination-links a.page-option;attr=href" json:"-"`
+
+	// listed reports whether Reviews, NextURL and PageURLs hold the parsed
+	// review listing page at URL.
+	listed bool
 }
 
 // XXX: SqrapeFieldSelect skips parsing other fields when parsing paginated fields.
@@ -59,7 +242,7 @@ func (b *LocalBusiness) SqrapeFieldSelect(fieldName string, context ...interface
 	}
 	// 1st field: is pagination
 	if context[0].(bool) == true {
-		return fieldName == "Reviews", nil
+		return fieldName == "Reviews" || fieldName == "NextURL" || fieldName == "PageURLs", nil
 	}
 	return true, nil
 }
@@ -67,9 +250,44 @@ func (b *LocalBusiness) SqrapeFieldSelect(fieldName string, context ...interface
 // XXX: SqrapePostFlight defines custom parsing logic for scraped fields.
 func (b *LocalBusiness) SqrapePostFlight(context ...interface{}) (err error) {
 	b.Name = strings.TrimSpace(b.Name)
+	b.PriceRange = strings.TrimSpace(b.PriceRange)
+	b.PriceLevel = strings.Count(b.PriceRange, "$")
+	b.Phone = normalizePhone(b.Phone)
+	b.Website = unwrapRedirect(b.WebsiteStr)
+	b.Claimed = strings.EqualFold(strings.TrimSpace(b.ClaimStatus), "claimed")
+	for i, c := range b.Categories {
+		b.Categories[i] = strings.TrimSpace(c)
+	}
 	return err
 }
 
+// OpenOn returns whether the business has opening hours on the weekday.
+func (b *LocalBusiness) OpenOn(day time.Weekday) bool {
+	for _, h := range b.Hours {
+		if h.Weekday == day && len(h.Intervals) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// HasCategory returns whether the business is listed under the category.
+func (b *LocalBusiness) HasCategory(category string) bool {
+	for _, c := range b.Categories {
+		if strings.EqualFold(c, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// notRecommendedPage defines a page of the "not currently recommended" reviews listing.
+type notRecommendedPage struct {
+	Reviews  []Review `csss:"div.not-recommended-reviews div.review;obj"`
+	NextURL  string   `csss:"div.not-recommended-reviews div.pagination-links a.next;attr=href"`
+	PageURLs []string `csss:"div.not-recommended-reviews div.pagination-links a.page-option;attr=href"`
+}
+
 // ReviewFilter defines filter data to filter reviews by.
 type ReviewFilter struct {
 	Type  string `json:"type"`
@@ -0,0 +1,131 @@
+package yelp
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	clockRe     = regexp.MustCompile(`(?i)(\d{1,2}):(\d{2})\s*([ap]\.?m\.?)?`)
+	nonDigitRe  = regexp.MustCompile(`\D`)
+	shortDateRe = regexp.MustCompile(`\d{1,2}/\d{1,2}/\d{4}`)
+	decimalRe   = regexp.MustCompile(`\d+(\.\d+)?`)
+	yearRe      = regexp.MustCompile(`\d{4}|\d{2}`)
+)
+
+const minutesPerDay = 24 * 60
+
+// parseWeekday parses a full or abbreviated english weekday name.
+func parseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) >= 3 {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.HasPrefix(strings.ToLower(d.String()), s[:3]) {
+				return d, nil
+			}
+		}
+	}
+	return time.Sunday, fmt.Errorf("invalid weekday %q", s)
+}
+
+// parseIntervals parses opening hours such as "11:00 am - 2:30 pm 5:00 pm - 10:00 pm".
+func parseIntervals(s string) (intervals []Interval, err error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if strings.Contains(s, "24 hours") {
+		return []Interval{{Open: 0, Close: minutesPerDay}}, nil
+	}
+
+	matches := clockRe.FindAllStringSubmatch(s, -1)
+	if len(matches)%2 != 0 {
+		return nil, fmt.Errorf("invalid opening hours %q", s)
+	}
+
+	for i := 0; i < len(matches); i += 2 {
+		open, err := parseClock(matches[i])
+		if err != nil {
+			return nil, err
+		}
+		close, err := parseClock(matches[i+1])
+		if err != nil {
+			return nil, err
+		}
+		if close <= open {
+			close += minutesPerDay
+		}
+		intervals = append(intervals, Interval{Open: open, Close: close})
+	}
+	return intervals, nil
+}
+
+// parseClock converts a clockRe match into minutes since midnight.
+func parseClock(m []string) (int, error) {
+	hour, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	if hour > 23 || minute > 59 {
+		return 0, fmt.Errorf("invalid time %q", m[0])
+	}
+
+	switch {
+	case strings.HasPrefix(m[3], "a"):
+		if hour == 12 {
+			hour = 0
+		}
+	case strings.HasPrefix(m[3], "p"):
+		if hour != 12 {
+			hour += 12
+		}
+	}
+	return hour*60 + minute, nil
+}
+
+// parseCount extracts the integer from counts such as "1,234 check-ins".
+func parseCount(s string) int {
+	n, _ := strconv.Atoi(nonDigitRe.ReplaceAllString(s, ""))
+	return n
+}
+
+// parseStarRating extracts the rating from titles such as "4.0 star rating".
+func parseStarRating(s string) float64 {
+	rating, _ := strconv.ParseFloat(decimalRe.FindString(s), 64)
+	return rating
+}
+
+// parseEliteYears parses elite years such as "2015, 2016" or "’15 ’16".
+func parseEliteYears(s string) (years []int) {
+	for _, y := range yearRe.FindAllString(s, -1) {
+		year, _ := strconv.Atoi(y)
+		if year < 100 {
+			year += 2000
+		}
+		years = append(years, year)
+	}
+	return years
+}
+
+// normalizePhone formats north american numbers as E.164 and trims anything else.
+func normalizePhone(s string) string {
+	digits := nonDigitRe.ReplaceAllString(s, "")
+	switch {
+	case len(digits) == 10:
+		return "+1" + digits
+	case len(digits) == 11 && digits[0] == '1':
+		return "+" + digits
+	}
+	return strings.TrimSpace(s)
+}
+
+// unwrapRedirect returns the target of a yelp redirect link.
+func unwrapRedirect(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	if target := u.Query().Get("url"); target != "" {
+		return target
+	}
+	return u.String()
+}
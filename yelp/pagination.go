@@ -0,0 +1,357 @@
+package yelp
+
+import (
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// maxReviewPages caps the number of pages walked per review listing.
+const maxReviewPages = 250
+
+// pageParser fetches and parses a listing page, returning its reviews and the
+// raw links to other pages of the listing.
+type pageParser func(pageURL string) (reviews []Review, links []string, err error)
+
+// resolveURL resolves a possibly relative link found on the page at base.
+func resolveURL(base, href string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	h, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return "", err
+	}
+	return b.ResolveReference(h).String(), nil
+}
+
+// withQuery returns rawURL with the query parameter key set to value,
+// preserving any existing query parameters.
+func withQuery(rawURL, key, value string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set(key, value)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// pagingParams defines the query parameters selecting a page of a listing.
+var pagingParams = []string{"start", "not_recommended_start"}
+
+// normalizePageURL canonicalizes a listing URL so that equivalent links compare equal.
+func normalizePageURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	for _, key := range pagingParams {
+		if q.Get(key) == "0" {
+			q.Del(key)
+		}
+	}
+	u.RawQuery = q.Encode()
+	u.Fragment = ""
+	return u.String(), nil
+}
+
+// listingQuery returns the query of a listing URL without its paging
+// parameters, e.g. its language, sort order or filters.
+func listingQuery(u *url.URL) string {
+	q := u.Query()
+	for _, key := range pagingParams {
+		q.Del(key)
+	}
+	return q.Encode()
+}
+
+// sameListing returns whether both URLs point to pages of the same listing,
+// i.e. they only differ by page.
+func sameListing(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return ua.Host == ub.Host && ua.Path == ub.Path && listingQuery(ua) == listingQuery(ub)
+}
+
+// withFirstPage returns a parser answering the already parsed page at
+// firstURL without fetching it again, and parsing other pages with parse.
+func withFirstPage(firstURL string, reviews []Review, links []string, parse pageParser) pageParser {
+	first, err := normalizePageURL(firstURL)
+	if err != nil {
+		return parse
+	}
+	return func(pageURL string) ([]Review, []string, error) {
+		if pageURL == first {
+			return reviews, links, nil
+		}
+		return parse(pageURL)
+	}
+}
+
+// pageFingerprint identifies a page by the reviews it lists.
+func pageFingerprint(reviews []Review) string {
+	ids := make([]string, len(reviews))
+	for i, r := range reviews {
+		ids[i] = r.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+// Fetch event types.
+const (
+	FetchPageStarted = "page_started"
+	FetchPageParsed  = "page_parsed"
+	FetchPageFailed  = "page_failed"
+	FetchDone        = "done"
+)
+
+// FetchEvent defines the progress of a review fetch. ReviewCount and Rating
+// are running totals over the distinct reviews fetched so far, across all
+// listings of the fetch.
+type FetchEvent struct {
+	Type  string `json:"type"`
+	URL   string `json:"url,omitempty"`
+	Error string `json:"error,omitempty"`
+	// PageReviews is the number of reviews listed on a parsed page.
+	PageReviews int     `json:"page_reviews,omitempty"`
+	PagesDone   int     `json:"pages_done"`
+	PagesTotal  int     `json:"pages_total"`
+	ReviewCount int     `json:"review_count"`
+	Rating      float64 `json:"rating"`
+}
+
+// fetchTracker counts the listing pages and reviews of a fetch across its
+// listings, reports them as events and carries its cancellation. A nil
+// tracker tracks nothing.
+type fetchTracker struct {
+	mu        sync.Mutex
+	done      int
+	total     int
+	failures  int
+	seen      map[string]bool
+	reviews   int
+	ratingSum float64
+	progress  func(FetchEvent)
+	cancel    <-chan struct{}
+}
+
+func newFetchTracker(opts FetchOptions) *fetchTracker {
+	return &fetchTracker{
+		seen:     map[string]bool{},
+		progress: opts.Progress,
+		cancel:   opts.Cancel,
+	}
+}
+
+// emit completes the event with the running totals and reports it. The
+// caller must hold t.mu so that events are reported in order.
+func (t *fetchTracker) emit(e FetchEvent) {
+	if t.progress == nil {
+		return
+	}
+	e.PagesDone, e.PagesTotal, e.ReviewCount = t.done, t.total, t.reviews
+	if t.reviews > 0 {
+		e.Rating = t.ratingSum / float64(t.reviews)
+	}
+	t.progress(e)
+}
+
+// discovered records pages queued for fetching.
+func (t *fetchTracker) discovered(n int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.total += n
+	t.mu.Unlock()
+}
+
+// started records a page about to be fetched.
+func (t *fetchTracker) started(pageURL string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.emit(FetchEvent{Type: FetchPageStarted, URL: pageURL})
+}
+
+// parsed records a page fetched and parsed along with its reviews.
+func (t *fetchTracker) parsed(pageURL string, page []Review) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.done++
+	for _, r := range page {
+		if r.ID != "" && t.seen[r.ID] {
+			continue
+		}
+		t.seen[r.ID] = true
+		t.reviews++
+		t.ratingSum += r.Rating
+	}
+	t.emit(FetchEvent{Type: FetchPageParsed, URL: pageURL, PageReviews: len(page)})
+}
+
+// failed records a page that could not be fetched or parsed.
+func (t *fetchTracker) failed(pageURL string, err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.done++
+	t.failures++
+	t.emit(FetchEvent{Type: FetchPageFailed, URL: pageURL, Error: err.Error()})
+}
+
+// finished reports the final reviews of the fetch, and its error if any. A
+// successful fetch reports every page as done.
+func (t *fetchTracker) finished(reviews []Review, err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err == nil {
+		t.done = t.total
+	}
+	t.reviews, t.ratingSum = len(reviews), 0
+	for _, r := range reviews {
+		t.ratingSum += r.Rating
+	}
+	e := FetchEvent{Type: FetchDone}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	t.emit(e)
+}
+
+// failedPages returns the number of pages that could not be fetched or parsed.
+func (t *fetchTracker) failedPages() int {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.failures
+}
+
+// canceled reports whether the fetch was canceled.
+func (t *fetchTracker) canceled() bool {
+	if t == nil || t.cancel == nil {
+		return false
+	}
+	select {
+	case <-t.cancel:
+		return true
+	default:
+		return false
+	}
+}
+
+// crawlListing walks a paginated review listing starting at startURL,
+// following the page links discovered on each page.
+//
+// Pages are fetched concurrently in waves, up to maxReviewPages. Pages listing
+// the same reviews as an already fetched page are treated as duplicates and
+// their links are not followed. Reviews are deduplicated by ID. Each page is
+// reported to the tracker, and once the tracker is canceled, no further pages
+// are fetched.
+func crawlListing(startURL string, parse pageParser, t *fetchTracker) (reviews []Review) {
+	start, err := normalizePageURL(startURL)
+	if err != nil {
+		log.Printf("invalid listing url %s: %v\n", startURL, err)
+		return nil
+	}
+
+	mu := &sync.Mutex{}
+	visited := map[string]bool{start: true}
+	fingerprints := map[string]bool{}
+	seenReviews := map[string]bool{}
+	wave := []string{start}
+	t.discovered(1)
+
+	for len(wave) > 0 && !t.canceled() {
+		var next []string
+		wg := &sync.WaitGroup{}
+
+		for _, pageURL := range wave {
+			wg.Add(1)
+			go func(pageURL string) {
+				defer wg.Done()
+				if t.canceled() {
+					t.failed(pageURL, ErrFetchCanceled)
+					return
+				}
+				log.Printf("fetching reviews on url %s\n", pageURL)
+				t.started(pageURL)
+
+				page, links, err := parse(pageURL)
+				if err != nil {
+					log.Printf("failed to fetch reviews on url %s: %v\n", pageURL, err)
+					t.failed(pageURL, err)
+					return
+				}
+				t.parsed(pageURL, page)
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				if fp := pageFingerprint(page); len(page) > 0 {
+					if fingerprints[fp] {
+						log.Printf("skipping duplicate page %s\n", pageURL)
+						return
+					}
+					fingerprints[fp] = true
+				}
+
+				for _, r := range page {
+					if r.ID != "" && seenReviews[r.ID] {
+						continue
+					}
+					seenReviews[r.ID] = true
+					reviews = append(reviews, r)
+				}
+
+				for _, link := range links {
+					if link == "" {
+						continue
+					}
+					abs, err := resolveURL(pageURL, link)
+					if err != nil {
+						continue
+					}
+					if abs, err = normalizePageURL(abs); err != nil || visited[abs] || !sameListing(start, abs) {
+						continue
+					}
+					if len(visited) >= maxReviewPages {
+						log.Printf("reached page cap of %d for %s\n", maxReviewPages, startURL)
+						return
+					}
+					visited[abs] = true
+					next = append(next, abs)
+					t.discovered(1)
+				}
+				log.Printf("done fetching reviews on url %s\n", pageURL)
+			}(pageURL)
+		}
+
+		wg.Wait()
+		wave = next
+	}
+	return reviews
+}
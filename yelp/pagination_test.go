@@ -0,0 +1,113 @@
+package yelp
+
+import "testing"
+
+func TestNormalizePageURL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"https://www.yelp.com/biz/foo", "https://www.yelp.com/biz/foo"},
+		{"https://www.yelp.com/biz/foo?start=0", "https://www.yelp.com/biz/foo"},
+		{"https://www.yelp.com/biz/foo?start=20", "https://www.yelp.com/biz/foo?start=20"},
+		{"https://www.yelp.com/biz/foo?start=20&osq=pizza", "https://www.yelp.com/biz/foo?osq=pizza&start=20"},
+		{"https://www.yelp.com/biz/foo?osq=pizza&start=0", "https://www.yelp.com/biz/foo?osq=pizza"},
+		{"https://www.yelp.com/biz/foo?l=fr&start=20#reviews", "https://www.yelp.com/biz/foo?l=fr&start=20"},
+		{"https://www.yelp.com/not_recommended_reviews/foo?not_recommended_start=0", "https://www.yelp.com/not_recommended_reviews/foo"},
+	}
+	for _, tt := range tests {
+		got, err := normalizePageURL(tt.in)
+		if err != nil {
+			t.Errorf("normalizePageURL(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("normalizePageURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWithQuery(t *testing.T) {
+	tests := []struct {
+		in, key, value, want string
+	}{
+		{"https://www.yelp.com/biz/foo", "l", "fr", "https://www.yelp.com/biz/foo?l=fr"},
+		{"https://www.yelp.com/biz/foo?osq=pizza", "l", "fr", "https://www.yelp.com/biz/foo?l=fr&osq=pizza"},
+		{"https://www.yelp.com/biz/foo?l=de", "l", "fr", "https://www.yelp.com/biz/foo?l=fr"},
+		{"https://www.yelp.com/biz/foo?osq=pizza&start=20", "start", "40", "https://www.yelp.com/biz/foo?osq=pizza&start=40"},
+		{"https://www.yelp.com/biz/foo#reviews", "l", "fr", "https://www.yelp.com/biz/foo?l=fr#reviews"},
+	}
+	for _, tt := range tests {
+		got, err := withQuery(tt.in, tt.key, tt.value)
+		if err != nil {
+			t.Errorf("withQuery(%q, %q, %q) error: %v", tt.in, tt.key, tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("withQuery(%q, %q, %q) = %q, want %q", tt.in, tt.key, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSameListing(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"https://www.yelp.com/biz/foo", "https://www.yelp.com/biz/foo?start=20", true},
+		{"https://www.yelp.com/biz/foo?osq=pizza", "https://www.yelp.com/biz/foo?osq=pizza&start=20", true},
+		{"https://www.yelp.com/biz/foo?l=fr", "https://www.yelp.com/biz/foo?start=20&l=fr", true},
+		{"https://www.yelp.com/biz/foo?l=fr", "https://www.yelp.com/biz/foo?start=20", false},
+		{"https://www.yelp.com/biz/foo", "https://www.yelp.com/biz/foo?sort_by=date_desc&start=20", false},
+		{"https://www.yelp.com/biz/foo", "https://www.yelp.com/biz/foo?start=20#reviews", true},
+		{"https://www.yelp.com/biz/foo", "https://www.yelp.com/biz/bar?start=20", false},
+		{"https://www.yelp.com/biz/foo", "https://www.yelp.ca/biz/foo?start=20", false},
+		{"https://www.yelp.com/not_recommended_reviews/foo", "https://www.yelp.com/not_recommended_reviews/foo?not_recommended_start=10", true},
+	}
+	for _, tt := range tests {
+		if got := sameListing(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameListing(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestResolveURL(t *testing.T) {
+	tests := []struct {
+		base, href, want string
+	}{
+		{"https://www.yelp.com/biz/foo", "/biz/foo?start=20", "https://www.yelp.com/biz/foo?start=20"},
+		{"https://www.yelp.com/biz/foo?osq=pizza&start=20", "?osq=pizza&start=40", "https://www.yelp.com/biz/foo?osq=pizza&start=40"},
+		{"https://www.yelp.com/biz/foo?l=fr", " /biz/foo?l=fr&start=20 ", "https://www.yelp.com/biz/foo?l=fr&start=20"},
+		{"https://www.yelp.com/biz/foo#reviews", "#hours", "https://www.yelp.com/biz/foo#hours"},
+		{"https://www.yelp.com/biz/foo", "https://www.yelp.com/biz/foo?start=40", "https://www.yelp.com/biz/foo?start=40"},
+	}
+	for _, tt := range tests {
+		got, err := resolveURL(tt.base, tt.href)
+		if err != nil {
+			t.Errorf("resolveURL(%q, %q) error: %v", tt.base, tt.href, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("resolveURL(%q, %q) = %q, want %q", tt.base, tt.href, got, tt.want)
+		}
+	}
+}
+
+func TestCrawlListingFirstPage(t *testing.T) {
+	fetched := map[string]int{}
+	parse := func(pageURL string) ([]Review, []string, error) {
+		fetched[pageURL]++
+		return []Review{{ID: pageURL}}, nil, nil
+	}
+	first := []Review{{ID: "1"}}
+	links := []string{"/biz/foo?osq=pizza&start=20", "/biz/foo?osq=pizza&sort_by=date_desc&start=20"}
+
+	reviews := crawlListing("https://www.yelp.com/biz/foo?osq=pizza",
+		withFirstPage("https://www.yelp.com/biz/foo?osq=pizza", first, links, parse), nil)
+
+	if len(fetched) != 1 || fetched["https://www.yelp.com/biz/foo?osq=pizza&start=20"] != 1 {
+		t.Errorf("fetched pages %v, want only the second page", fetched)
+	}
+	if len(reviews) != 2 {
+		t.Errorf("got %d reviews, want 2", len(reviews))
+	}
+}
@@ -1,13 +1,15 @@
 package yelp
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"log"
-	"net/http"
 	"net/url"
+	"path"
+	"sort"
 	"strconv"
 	"strings"
-	"sync"
 
 	"github.com/cathalgarvey/sqrape"
 	"github.com/hashicorp/golang-lru"
@@ -19,10 +21,6 @@ func init() {
 	cache, _ = lru.NewARC(128)
 }
 
-func getPage(url string) (r *http.Response, err error) {
-	return http.Get(url)
-}
-
 func isPaginate(rawURL string) bool {
 	u, err := url.Parse(rawURL)
 	if err != nil {
@@ -35,45 +33,179 @@ func isPaginate(rawURL string) bool {
 
 func NewBusiness(url string) (b LocalBusiness, err error) {
 	b.URL = url
-	r, err := getPage(url)
+	body, err := getPage(url)
 	if err != nil {
 		return b, err
 	}
-	err = sqrape.ExtractHTMLReader(r.Body, &b, isPaginate(url))
+	err = sqrape.ExtractHTMLReader(bytes.NewReader(body), &b, isPaginate(url))
+	b.listed = err == nil
 	return b, err
 }
 
+// ErrFetchCanceled is returned when a fetch is canceled before completion.
+var ErrFetchCanceled = errors.New("fetch canceled")
+
+// FetchOptions defines optional review listings fetched alongside the main listing.
+type FetchOptions struct {
+	// NotRecommended also fetches the "not currently recommended" reviews.
+	NotRecommended bool `json:"not_recommended"`
+	// Languages also fetches the review listing of each language or locale, e.g. "fr" or "de_DE".
+	Languages []string `json:"languages"`
+	// Refresh bypasses cached reviews.
+	Refresh bool `json:"refresh"`
+	// Cancel stops the fetch when closed.
+	Cancel <-chan struct{} `json:"-"`
+	// Progress is called with an event as each listing page is started,
+	// parsed or failed, and once the fetch is done. Events are reported one
+	// at a time, in order, from the fetching goroutines.
+	Progress func(FetchEvent) `json:"-"`
+}
+
+func (o FetchOptions) cacheKey(url string) string {
+	if o.NotRecommended {
+		url += "#not_recommended"
+	}
+	if len(o.Languages) > 0 {
+		url += "#" + strings.Join(o.Languages, ",")
+	}
+	return url
+}
+
 // FetchReviews aggregates all reviews for the business.
 func (b *LocalBusiness) FetchReviews() {
-	if cache.Contains(b.URL) {
+	b.FetchReviewsWithOptions(FetchOptions{})
+}
+
+// FetchReviewsWithOptions aggregates all reviews for the business, including
+// the optional listings enabled in opts.
+//
+// If opts.Cancel is closed before completion, the reviews fetched so far are
+// kept but not cached, and ErrFetchCanceled is returned. Reviews are not cached either
+// when listing pages failed, so that a later fetch retries them.
+func (b *LocalBusiness) FetchReviewsWithOptions(opts FetchOptions) error {
+	key := opts.cacheKey(b.URL)
+	if !opts.Refresh && cache.Contains(key) {
 		log.Printf("found business reviews for %s in cache\n", b.Name)
-		val, _ := cache.Get(b.URL)
+		val, _ := cache.Get(key)
 		b.Reviews = val.([]Review)
-		return
+		// The cached listing counts as the single page fetched with the business.
+		t := newFetchTracker(opts)
+		t.discovered(1)
+		t.finished(b.Reviews, nil)
+		return nil
 	}
 
-	wg := &sync.WaitGroup{}
 	log.Printf("fetching business reviews %s\n", b.Name)
+	t := newFetchTracker(opts)
+	parse := reviewPageParser("en")
+	if b.listed {
+		// The first page was parsed along with the business.
+		for i := range b.Reviews {
+			b.Reviews[i].localize("en")
+		}
+		parse = withFirstPage(b.URL, b.Reviews, append(b.PageURLs, b.NextURL), parse)
+		b.listed = false
+	}
+	b.Reviews = crawlListing(b.URL, parse, t)
+	for _, locale := range opts.Languages {
+		b.Reviews = mergeReviews(b.Reviews, b.fetchLocalizedReviews(locale, t))
+	}
+	if opts.NotRecommended {
+		b.Reviews = mergeReviews(b.Reviews, b.fetchNotRecommendedReviews(t))
+	}
 
-	for url := range b.paginationURLs() {
-		wg.Add(1)
-		go func(url string) {
-			log.Printf("fetching reviews on url %s\n", url)
-			defer wg.Done()
+	if t.canceled() {
+		log.Printf("canceled fetching business reviews %s\n", b.Name)
+		t.finished(b.Reviews, ErrFetchCanceled)
+		return ErrFetchCanceled
+	}
+	if n := t.failedPages(); n > 0 {
+		log.Printf("not caching business reviews for %s: %d pages failed\n", b.Name, n)
+	} else {
+		cache.Add(key, b.Reviews)
+		log.Printf("added business reviews for %s to cache\n", b.Name)
+	}
+	t.finished(b.Reviews, nil)
+	return nil
+}
 
-			p, err := NewBusiness(url)
-			if err != nil {
-				return
-			}
+// reviewPageParser parses pages of a review listing, localizing reviews with
+// the listing's language or locale, e.g. "en" for the main listing.
+func reviewPageParser(locale string) pageParser {
+	return func(pageURL string) ([]Review, []string, error) {
+		p, err := NewBusiness(pageURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range p.Reviews {
+			p.Reviews[i].localize(locale)
+		}
+		return p.Reviews, append(p.PageURLs, p.NextURL), nil
+	}
+}
 
-			b.Reviews = append(b.Reviews, p.Reviews...)
-			log.Printf("done fetching reviews on url %s\n", url)
-		}(url)
+// parseNotRecommendedPage parses a page of the "not currently recommended" listing.
+func parseNotRecommendedPage(pageURL string) ([]Review, []string, error) {
+	body, err := getPage(pageURL)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	wg.Wait()
-	cache.Add(b.URL, b.Reviews)
-	log.Printf("added business reviews for %s to cache\n", b.Name)
+	var p notRecommendedPage
+	if err := sqrape.ExtractHTMLReader(bytes.NewReader(body), &p); err != nil {
+		return nil, nil, err
+	}
+	for i := range p.Reviews {
+		p.Reviews[i].NotRecommended = true
+	}
+	return p.Reviews, append(p.PageURLs, p.NextURL), nil
+}
+
+// fetchLocalizedReviews walks the review listing of the given language or locale.
+func (b *LocalBusiness) fetchLocalizedReviews(locale string, t *fetchTracker) []Review {
+	listingURL, err := withQuery(b.URL, "l", locale)
+	if err != nil {
+		log.Printf("failed to build %s listing url for %s: %v\n", locale, b.URL, err)
+		return nil
+	}
+	return crawlListing(listingURL, reviewPageParser(locale), t)
+}
+
+// mergeReviews appends the reviews of other that are not already in reviews.
+// Reviews without an ID are always appended.
+func mergeReviews(reviews, other []Review) []Review {
+	seen := make(map[string]bool, len(reviews))
+	for _, r := range reviews {
+		seen[r.ID] = true
+	}
+	for _, r := range other {
+		if r.ID != "" && seen[r.ID] {
+			continue
+		}
+		seen[r.ID] = true
+		reviews = append(reviews, r)
+	}
+	return reviews
+}
+
+// notRecommendedURL returns the "not currently recommended" listing of the business.
+func (b *LocalBusiness) notRecommendedURL() (string, error) {
+	u, err := url.Parse(b.URL)
+	if err != nil {
+		return "", err
+	}
+	u.Path = "/not_recommended_reviews/" + path.Base(u.Path)
+	return u.String(), nil
+}
+
+// fetchNotRecommendedReviews walks the "not currently recommended" listing.
+func (b *LocalBusiness) fetchNotRecommendedReviews(t *fetchTracker) []Review {
+	listingURL, err := b.notRecommendedURL()
+	if err != nil {
+		log.Printf("failed to build not recommended url for %s: %v\n", b.URL, err)
+		return nil
+	}
+	return crawlListing(listingURL, parseNotRecommendedPage, t)
 }
 
 // FilterReviews filters down the list of reviews based on the provided filters.
@@ -89,9 +221,29 @@ func (b *LocalBusiness) FilterReviews(filters []ReviewFilter) (err error) {
 			length, _ := strconv.Atoi(f.Value)
 			filterFuncs = append(filterFuncs, makeFilterMinAuthorReviews(length))
 
+		case "min_author_friends":
+			count, _ := strconv.Atoi(f.Value)
+			filterFuncs = append(filterFuncs, makeFilterMinAuthorFriends(count))
+
+		case "min_author_credibility":
+			score, _ := strconv.ParseFloat(f.Value, 64)
+			filterFuncs = append(filterFuncs, makeFilterMinAuthorCredibility(score))
+
+		case "exclude_extreme_single_review":
+			if exclude, _ := strconv.ParseBool(f.Value); exclude {
+				filterFuncs = append(filterFuncs, filterExtremeSingleReview)
+			}
+
+		case "recommendation":
+			filterFuncs = append(filterFuncs, makeFilterRecommendation(f.Value))
+
 		case "max_proximity":
 			filterFuncs = append(filterFuncs, makeFilterMaxProximity(f.Value, b.Address))
 
+		case "category", "min_price", "max_price", "claimed", "open_on":
+			// Business filters are evaluated by MatchesFilters.
+			continue
+
 		default:
 			log.Printf("filter %v unsupported\n", f)
 			continue
@@ -113,6 +265,70 @@ func (b *LocalBusiness) FilterReviews(filters []ReviewFilter) (err error) {
 	return
 }
 
+// reviewSortKeys defines the "less" function of each review sort key.
+var reviewSortKeys = map[string]func(a, b *Review) bool{
+	"date":   func(a, b *Review) bool { return a.Date.Before(b.Date) },
+	"rating": func(a, b *Review) bool { return a.Rating < b.Rating },
+	"useful": func(a, b *Review) bool { return a.Votes.Useful < b.Votes.Useful },
+	"length": func(a, b *Review) bool { return len(a.Description) < len(b.Description) },
+}
+
+// SortReviews sorts reviews by "date", "rating", "useful" or "length",
+// ascending, or descending when the key is prefixed with "-".
+func SortReviews(reviews []Review, key string) error {
+	desc := strings.HasPrefix(key, "-")
+	less, ok := reviewSortKeys[strings.TrimPrefix(key, "-")]
+	if !ok {
+		return fmt.Errorf("sort key %s unsupported", key)
+	}
+	sort.SliceStable(reviews, func(i, j int) bool {
+		if desc {
+			return less(&reviews[j], &reviews[i])
+		}
+		return less(&reviews[i], &reviews[j])
+	})
+	return nil
+}
+
+// MatchesFilters returns whether the business satisfies all business filters.
+//
+// Review filters are ignored.
+func (b *LocalBusiness) MatchesFilters(filters []ReviewFilter) bool {
+	for _, f := range filters {
+		switch f.Type {
+		case "category":
+			if !b.HasCategory(f.Value) {
+				return false
+			}
+
+		case "min_price":
+			level, _ := strconv.Atoi(f.Value)
+			if b.PriceLevel < level {
+				return false
+			}
+
+		case "max_price":
+			level, _ := strconv.Atoi(f.Value)
+			if b.PriceLevel > level {
+				return false
+			}
+
+		case "claimed":
+			claimed, _ := strconv.ParseBool(f.Value)
+			if b.Claimed != claimed {
+				return false
+			}
+
+		case "open_on":
+			day, err := parseWeekday(f.Value)
+			if err != nil || !b.OpenOn(day) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 type reviewFilterFunc func(r *Review) bool
 
 // matchAny returns a boolean if the review matches any of the provided filters.
@@ -140,6 +356,19 @@ func makeFilterMaxProximity(level string, addr Address) reviewFilterFunc {
 	}
 }
 
+// makeFilterRecommendation keeps "recommended" or "not_recommended" reviews only.
+func makeFilterRecommendation(status string) reviewFilterFunc {
+	return func(r *Review) bool {
+		switch status {
+		case "recommended":
+			return r.NotRecommended
+		case "not_recommended":
+			return !r.NotRecommended
+		}
+		return false
+	}
+}
+
 func makeFilterMinReviewLength(n int) reviewFilterFunc {
 	return func(r *Review) bool {
 		return len(r.Description) < n
@@ -152,28 +381,38 @@ func makeFilterMinAuthorReviews(n int) reviewFilterFunc {
 	}
 }
 
+func makeFilterMinAuthorFriends(n int) reviewFilterFunc {
+	return func(r *Review) bool {
+		return r.Author.FriendCount < n
+	}
+}
+
+func makeFilterMinAuthorCredibility(score float64) reviewFilterFunc {
+	return func(r *Review) bool {
+		return r.Author.Credibility() < score
+	}
+}
+
+// filterExtremeSingleReview matches 1 or 5 star reviews from authors with a single review.
+func filterExtremeSingleReview(r *Review) bool {
+	return r.Author.ReviewCount == 1 && (r.Rating == 1 || r.Rating == 5)
+}
+
 // CalculateRating returns the newly calculated rating score.
 //
 // It only takes into consideration the number of reviews it has in-memory.
 func (b *LocalBusiness) CalculateRating() float64 {
-	if len(b.Reviews) == 0 {
-		return 0.0
-	}
-
-	var sum float64
-	for _, r := range b.Reviews {
-		sum += r.Rating
-	}
-	return sum / float64(len(b.Reviews))
+	return meanRating(b.Reviews)
 }
 
-func (b LocalBusiness) paginationURLs() (urls chan string) {
-	urls = make(chan string, 64)
-	go func() {
-		for i := 0; i < b.ReviewCount; i += 20 {
-			urls <- fmt.Sprintf("%s?start=%d", b.URL, i)
-		}
-		close(urls)
-	}()
-	return urls
+// CalculateRatingWith returns the rating score computed by the named rating model.
+func (b *LocalBusiness) CalculateRatingWith(model string) (float64, error) {
+	if model == "" {
+		return b.CalculateRating(), nil
+	}
+	m, ok := ratingModels[model]
+	if !ok {
+		return 0, fmt.Errorf("rating model %s unsupported", model)
+	}
+	return m(b.Reviews), nil
 }
@@ -0,0 +1,51 @@
+package yelp
+
+// RatingModel computes a rating score from a set of reviews.
+type RatingModel func(reviews []Review) float64
+
+var ratingModels = map[string]RatingModel{
+	"mean":                        meanRating,
+	"useful_weighted":             usefulWeightedRating,
+	"recommended_mean":            recommendedOnly(meanRating),
+	"recommended_useful_weighted": recommendedOnly(usefulWeightedRating),
+}
+
+// recommendedOnly applies the model to recommended reviews only.
+func recommendedOnly(m RatingModel) RatingModel {
+	return func(reviews []Review) float64 {
+		recommended := make([]Review, 0, len(reviews))
+		for _, r := range reviews {
+			if !r.NotRecommended {
+				recommended = append(recommended, r)
+			}
+		}
+		return m(recommended)
+	}
+}
+
+// weightedRating returns the mean rating where each review is weighted by weight.
+func weightedRating(reviews []Review, weight func(r *Review) float64) float64 {
+	var sum, total float64
+	for i := range reviews {
+		w := weight(&reviews[i])
+		sum += reviews[i].Rating * w
+		total += w
+	}
+	if total == 0 {
+		return 0.0
+	}
+	return sum / total
+}
+
+func meanRating(reviews []Review) float64 {
+	return weightedRating(reviews, func(r *Review) float64 {
+		return 1
+	})
+}
+
+// usefulWeightedRating weights each review by one plus its useful votes.
+func usefulWeightedRating(reviews []Review) float64 {
+	return weightedRating(reviews, func(r *Review) float64 {
+		return 1 + float64(r.Votes.Useful)
+	})
+}
@@ -0,0 +1,302 @@
+package yelp
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TrackedBusiness defines a business refreshed on a schedule.
+type TrackedBusiness struct {
+	URL string `json:"url"`
+	// Schedule is a cron expression. When empty, the schedule of Group is used.
+	Schedule   string    `json:"schedule,omitempty"`
+	Group      string    `json:"group,omitempty"`
+	BusinessID string    `json:"business_id,omitempty"`
+	Running    bool      `json:"running"`
+	LastRun    time.Time `json:"last_run"`
+	LastStatus string    `json:"last_status,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+	NextRun    time.Time `json:"next_run"`
+}
+
+// schedulerState defines the persisted state of a Scheduler.
+type schedulerState struct {
+	Groups     map[string]string  `json:"groups"`
+	Businesses []*TrackedBusiness `json:"businesses"`
+}
+
+// Scheduler periodically refreshes tracked businesses and records a snapshot
+// of each run in the store.
+//
+// A business is never refreshed twice at the same time; runs falling due
+// while the previous run is still in progress are skipped.
+type Scheduler struct {
+	// Options defines the review listings fetched on each refresh.
+	Options FetchOptions
+	// OnRefresh, if set, is called after each successful refresh.
+	OnRefresh func(b *LocalBusiness, snap Snapshot)
+
+	path    string
+	store   *Store
+	mu      sync.Mutex
+	groups  map[string]string
+	tracked map[string]*TrackedBusiness
+	stop    chan struct{}
+}
+
+// OpenScheduler opens the scheduler persisted in the store.
+func OpenScheduler(store *Store) (*Scheduler, error) {
+	s := &Scheduler{
+		path:    filepath.Join(store.Dir(), "schedule.json"),
+		store:   store,
+		groups:  map[string]string{},
+		tracked: map[string]*TrackedBusiness{},
+	}
+
+	var state schedulerState
+	if err := readJSON(s.path, &state); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for g, spec := range state.Groups {
+		s.groups[g] = spec
+	}
+	for _, t := range state.Businesses {
+		t.Running = false
+		s.tracked[t.URL] = t
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reschedule(time.Now())
+	return s, nil
+}
+
+// save persists the scheduler. The caller must hold s.mu.
+func (s *Scheduler) save() {
+	state := schedulerState{Groups: s.groups, Businesses: s.sorted()}
+	if err := writeJSON(s.path, state); err != nil {
+		log.Printf("failed to save schedule %s: %v\n", s.path, err)
+	}
+}
+
+// sorted returns the tracked businesses by URL. The caller must hold s.mu.
+func (s *Scheduler) sorted() []*TrackedBusiness {
+	tracked := make([]*TrackedBusiness, 0, len(s.tracked))
+	for _, t := range s.tracked {
+		tracked = append(tracked, t)
+	}
+	sort.Slice(tracked, func(i, j int) bool {
+		return tracked[i].URL < tracked[j].URL
+	})
+	return tracked
+}
+
+// specFor returns the cron expression of a tracked business. The caller must hold s.mu.
+func (s *Scheduler) specFor(t *TrackedBusiness) string {
+	if t.Schedule != "" {
+		return t.Schedule
+	}
+	return s.groups[t.Group]
+}
+
+// reschedule computes the next run of every tracked business. The caller must hold s.mu.
+func (s *Scheduler) reschedule(now time.Time) {
+	for _, t := range s.tracked {
+		t.NextRun = time.Time{}
+		if cron, err := parseCron(s.specFor(t)); err == nil {
+			t.NextRun = cron.Next(now)
+		}
+	}
+}
+
+// Track registers a business to refresh on the cron schedule spec, or on the
+// schedule of group when spec is empty.
+func (s *Scheduler) Track(url, spec, group string) (TrackedBusiness, error) {
+	if url == "" {
+		return TrackedBusiness{}, fmt.Errorf("tracked business has no url")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if spec == "" && s.groups[group] == "" {
+		return TrackedBusiness{}, fmt.Errorf("no schedule for %s and group %q has none", url, group)
+	}
+	if spec != "" {
+		if _, err := parseCron(spec); err != nil {
+			return TrackedBusiness{}, err
+		}
+	}
+
+	t, ok := s.tracked[url]
+	if !ok {
+		t = &TrackedBusiness{URL: url}
+		s.tracked[url] = t
+	}
+	t.Schedule, t.Group = spec, group
+	s.reschedule(time.Now())
+	s.save()
+	return *t, nil
+}
+
+// Untrack stops refreshing a business.
+func (s *Scheduler) Untrack(url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tracked, url)
+	s.save()
+}
+
+// SetGroupSchedule sets the cron schedule shared by the businesses of a group.
+func (s *Scheduler) SetGroupSchedule(group, spec string) error {
+	if _, err := parseCron(spec); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.groups[group] = spec
+	s.reschedule(time.Now())
+	s.save()
+	return nil
+}
+
+// Groups returns the cron schedule of each group.
+func (s *Scheduler) Groups() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	groups := make(map[string]string, len(s.groups))
+	for g, spec := range s.groups {
+		groups[g] = spec
+	}
+	return groups
+}
+
+// Status returns the schedule and last run of every tracked business.
+func (s *Scheduler) Status() []TrackedBusiness {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := make([]TrackedBusiness, 0, len(s.tracked))
+	for _, t := range s.sorted() {
+		status = append(status, *t)
+	}
+	return status
+}
+
+// Start checks for due businesses every minute until Stop is called.
+func (s *Scheduler) Start() {
+	s.stop = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				s.runDue(now)
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler. Refreshes in progress run to completion.
+func (s *Scheduler) Stop() {
+	close(s.stop)
+}
+
+// runDue starts a refresh of each business whose next run has passed.
+func (s *Scheduler) runDue(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.tracked {
+		if t.NextRun.IsZero() || t.NextRun.After(now) {
+			continue
+		}
+		if cron, err := parseCron(s.specFor(t)); err == nil {
+			t.NextRun = cron.Next(now)
+		}
+		if t.Running {
+			log.Printf("skipping refresh of %s, previous run still in progress\n", t.URL)
+			continue
+		}
+
+		t.Running = true
+		go s.refresh(t.URL)
+	}
+	s.save()
+}
+
+// refresh scrapes a tracked business and records a snapshot.
+func (s *Scheduler) refresh(url string) {
+	log.Printf("refreshing tracked business %s\n", url)
+	b, snap, err := s.scrape(url)
+
+	s.mu.Lock()
+	if t, ok := s.tracked[url]; ok {
+		t.Running = false
+		t.LastRun = snap.Time
+		t.LastStatus, t.LastError = "OK", ""
+		if err != nil {
+			t.LastStatus, t.LastError = "ERROR", err.Error()
+		} else {
+			t.BusinessID = b.ID
+		}
+		s.save()
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		log.Printf("failed to refresh %s: %v\n", url, err)
+		return
+	}
+	if s.OnRefresh != nil {
+		s.OnRefresh(&b, snap)
+	}
+}
+
+func (s *Scheduler) scrape(url string) (b LocalBusiness, snap Snapshot, err error) {
+	snap.Time = time.Now()
+	b, err = NewBusiness(url)
+	if err != nil {
+		return b, snap, err
+	}
+
+	opts := s.Options
+	opts.Refresh = true
+	if err = b.FetchReviewsWithOptions(opts); err != nil {
+		return b, snap, err
+	}
+
+	known := map[string]bool{}
+	if prev, err := s.store.LoadBusiness(b.ID); err == nil {
+		for _, r := range prev.Reviews {
+			known[r.ID] = true
+		}
+	}
+	for _, r := range b.Reviews {
+		if !known[r.ID] {
+			snap.NewReviews = append(snap.NewReviews, r.ID)
+		}
+	}
+
+	snap.AggregateRating = b.AggregateRating
+	snap.ReviewCount = b.ReviewCount
+	snap.Rating = b.CalculateRating()
+	snap.ScrapedReviews = len(b.Reviews)
+
+	if err := s.store.SaveBusiness(&b); err != nil {
+		return b, snap, err
+	}
+	return b, snap, s.store.AppendSnapshot(b.ID, snap)
+}
@@ -0,0 +1,96 @@
+package yelp
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/cathalgarvey/sqrape"
+)
+
+// searchResult defines a business listed on a search results page.
+type searchResult struct {
+	ID             string `csss:"div.search-result;attr=data-biz-id"`
+	Name           string `csss:"a.biz-name;text"`
+	Href           string `csss:"a.biz-name;attr=href"`
+	RatingStr      string `csss:"div.biz-rating div.i-stars;attr=title"`
+	ReviewCountStr string `csss:"div.biz-rating span.review-count;text"`
+}
+
+// searchPage defines a page of search results.
+type searchPage struct {
+	Results []searchResult `csss:"li.regular-search-result;obj"`
+	NextURL string         `csss:"div.search-pagination a.next;attr=href"`
+}
+
+// searchURL returns the first search results page for term near location.
+func searchURL(term, location string) string {
+	q := url.Values{"find_desc": {term}, "find_loc": {location}}
+	return baseURL + "/search?" + q.Encode()
+}
+
+// businessURL resolves a search result link into a canonical business URL.
+func businessURL(pageURL, href string) (string, error) {
+	abs, err := resolveURL(pageURL, href)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(abs)
+	if err != nil {
+		return "", err
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String(), nil
+}
+
+// Search returns stubs of the businesses listed for term near location,
+// following the results pagination for up to maxPages pages.
+//
+// Stubs only carry the ID, name, URL, rating and review count; pass their URL
+// to NewBusiness to scrape the full business.
+func Search(term, location string, maxPages int) (businesses []LocalBusiness, err error) {
+	seen := map[string]bool{}
+	visited := map[string]bool{}
+
+	for pageURL := searchURL(term, location); pageURL != "" && len(visited) < maxPages; {
+		visited[pageURL] = true
+		log.Printf("fetching search results on url %s\n", pageURL)
+
+		body, err := getPage(pageURL)
+		if err != nil {
+			return businesses, err
+		}
+		var p searchPage
+		if err := sqrape.ExtractHTMLReader(bytes.NewReader(body), &p); err != nil {
+			return businesses, fmt.Errorf("failed to parse search results %s: %v", pageURL, err)
+		}
+
+		for _, res := range p.Results {
+			u, err := businessURL(pageURL, res.Href)
+			if err != nil || res.Href == "" || seen[u] {
+				continue
+			}
+			seen[u] = true
+			businesses = append(businesses, LocalBusiness{
+				ID:              res.ID,
+				Name:            strings.TrimSpace(res.Name),
+				URL:             u,
+				AggregateRating: parseStarRating(res.RatingStr),
+				ReviewCount:     parseCount(res.ReviewCountStr),
+			})
+		}
+
+		cur := pageURL
+		pageURL = ""
+		if p.NextURL != "" {
+			next, err := resolveURL(cur, p.NextURL)
+			if err == nil && !visited[next] {
+				pageURL = next
+			}
+		}
+	}
+	return businesses, nil
+}
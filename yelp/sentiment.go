@@ -0,0 +1,138 @@
+package yelp
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+const (
+	// negationScalar scales the valence of negated words.
+	negationScalar = -0.74
+	// negationWindow is the number of preceding words checked for a negation.
+	negationWindow = 3
+	// sentimentNorm approximates the maximum expected valence sum when
+	// normalizing scores to [-1, 1].
+	sentimentNorm = 15
+	// mismatchSentiment is the sentiment opposing the star rating beyond which
+	// a review is flagged as a mismatch.
+	mismatchSentiment = 0.5
+)
+
+var wordRe = regexp.MustCompile(`[\p{L}'’]+`)
+
+// tokenize returns the lowercase words of text. Typographic apostrophes are
+// replaced with ASCII ones, e.g. "don’t" becomes "don't".
+func tokenize(text string) []string {
+	words := wordRe.FindAllString(strings.ToLower(text), -1)
+	for i, w := range words {
+		words[i] = strings.Trim(strings.Replace(w, "’", "'", -1), "'")
+	}
+	return words
+}
+
+func isNegation(word string) bool {
+	return sentimentNegations[word] || strings.HasSuffix(word, "n't")
+}
+
+// SentimentScore returns the sentiment of text, from -1 (very negative) to 1
+// (very positive), using an embedded lexicon.
+//
+// Within each sentence, valences are intensified or dampened by preceding
+// booster words, inverted by negations in the preceding three words, and words
+// after "but" outweigh the words before it.
+func SentimentScore(text string) float64 {
+	var sum float64
+	for _, sentence := range sentenceRe.FindAllString(text, -1) {
+		sum += sentenceValence(tokenize(sentence))
+	}
+	return sum / math.Sqrt(sum*sum+sentimentNorm)
+}
+
+// sentenceValence returns the summed valence of the words of a sentence.
+func sentenceValence(words []string) float64 {
+	valences := make([]float64, len(words))
+	but := -1
+	for i, w := range words {
+		if w == "but" && but < 0 {
+			but = i
+		}
+
+		v, ok := sentimentLexicon[w]
+		if !ok {
+			continue
+		}
+		if i > 0 {
+			if boost, ok := sentimentBoosters[words[i-1]]; ok {
+				v += math.Copysign(boost, v)
+			}
+		}
+		for j := i - 1; j >= 0 && j >= i-negationWindow; j-- {
+			if isNegation(words[j]) {
+				v *= negationScalar
+				break
+			}
+		}
+		valences[i] = v
+	}
+
+	var sum float64
+	for i, v := range valences {
+		switch {
+		case but < 0:
+		case i < but:
+			v *= 0.5
+		case i > but:
+			v *= 1.5
+		}
+		sum += v
+	}
+	return sum
+}
+
+// Sentiment returns the sentiment of the review text, from -1 to 1.
+func (r *Review) Sentiment() float64 {
+	return SentimentScore(r.Description)
+}
+
+// SentimentMismatch reports whether the review text contradicts its star
+// rating, such as a glowing text with a one star rating.
+func (r *Review) SentimentMismatch() bool {
+	return sentimentMismatch(r.Rating, r.Sentiment())
+}
+
+func sentimentMismatch(rating, sentiment float64) bool {
+	return (rating >= 4 && sentiment <= -mismatchSentiment) ||
+		(rating > 0 && rating <= 2 && sentiment >= mismatchSentiment)
+}
+
+// SentimentStats defines the text sentiment of a population of reviews.
+type SentimentStats struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	// Mismatches holds the IDs of reviews whose text contradicts their rating.
+	Mismatches []string `json:"mismatches"`
+}
+
+// SentimentStats returns the mean text sentiment of the reviews and flags
+// reviews whose text contradicts their star rating.
+func (b *LocalBusiness) SentimentStats() (stats SentimentStats) {
+	stats.Mismatches = []string{}
+	var sum float64
+	for i := range b.Reviews {
+		r := &b.Reviews[i]
+		if r.Description == "" {
+			continue
+		}
+		sentiment := r.Sentiment()
+		sum += sentiment
+		stats.Count++
+		if sentimentMismatch(r.Rating, sentiment) {
+			stats.Mismatches = append(stats.Mismatches, r.ID)
+		}
+	}
+	if stats.Count > 0 {
+		stats.Mean = sum / float64(stats.Count)
+	}
+	return stats
+}
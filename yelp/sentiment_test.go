@@ -0,0 +1,38 @@
+package yelp
+
+import "testing"
+
+func TestSentimentScoreSentences(t *testing.T) {
+	// The negation of the first sentence must not flip "great".
+	if got := SentimentScore("The service was not bad. Great food!"); got <= 0.3 {
+		t.Errorf("negation across sentences: score %.3f, want clearly positive", got)
+	}
+
+	// "but" only reweights the words of its own sentence.
+	alone := SentimentScore("The food was great.")
+	if got := SentimentScore("The food was great. The parking was small but fine."); got < alone {
+		t.Errorf("but across sentences: score %.3f, want at least %.3f", got, alone)
+	}
+	within := SentimentScore("The food was great but the service was terrible.")
+	if within >= 0 {
+		t.Errorf("but within a sentence: score %.3f, want negative", within)
+	}
+}
+
+func TestSentimentScoreNegation(t *testing.T) {
+	tests := []struct {
+		text     string
+		positive bool
+	}{
+		{"I like it", true},
+		{"I don't like it", false},
+		{"I don’t like it", false},
+		{"Not good at all", false},
+		{"It was never bad", true},
+	}
+	for _, tt := range tests {
+		if got := SentimentScore(tt.text); (got > 0) != tt.positive {
+			t.Errorf("SentimentScore(%q) = %.3f, want positive %v", tt.text, got, tt.positive)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package yelp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReviewChange records a stored review whose text or rating changed between scrapes.
+type ReviewChange struct {
+	BusinessID     string    `json:"business_id"`
+	ReviewID       string    `json:"review_id"`
+	AuthorID       string    `json:"author_id"`
+	OldRating      float64   `json:"old_rating"`
+	NewRating      float64   `json:"new_rating"`
+	OldDescription string    `json:"old_description"`
+	NewDescription string    `json:"new_description"`
+	DetectedAt     time.Time `json:"detected_at"`
+}
+
+// Store persists scraped businesses as JSON documents on disk.
+type Store struct {
+	dir string
+	mu  sync.RWMutex
+	// version counts the businesses saved, to invalidate the review index.
+	version int
+
+	indexMu      sync.Mutex
+	index        *ReviewIndex
+	indexVersion int
+}
+
+// Dir returns the directory the store is rooted at.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// OpenStore opens a store rooted at dir, creating it if necessary.
+func OpenStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "businesses"), 0755); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir}, nil
+}
+
+func (s *Store) path(kind, id string) string {
+	return filepath.Join(s.dir, kind, storeKey(id)+".json")
+}
+
+// storeKey makes an ID safe to use as a file name.
+func storeKey(id string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(id)
+}
+
+func (s *Store) read(path string, v interface{}) error {
+	return readJSON(path, v)
+}
+
+func (s *Store) write(path string, v interface{}) error {
+	return writeJSON(path, v)
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeJSON atomically replaces the document at path.
+func writeJSON(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// SaveBusiness persists the business along with its reviews.
+//
+// Reviews whose text or rating differ from the stored copy are recorded as ReviewChanges.
+func (s *Store) SaveBusiness(b *LocalBusiness) error {
+	if b.ID == "" {
+		return fmt.Errorf("business %s has no id", b.URL)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var prev LocalBusiness
+	if err := s.read(s.path("businesses", b.ID), &prev); err == nil {
+		if changes := diffReviews(&prev, b); len(changes) > 0 {
+			var recorded []ReviewChange
+			s.read(s.path("changes", b.ID), &recorded)
+			if err := s.write(s.path("changes", b.ID), append(recorded, changes...)); err != nil {
+				return err
+			}
+		}
+	}
+	s.version++
+	return s.write(s.path("businesses", b.ID), b)
+}
+
+// diffReviews returns the reviews of next whose rating or text differ from prev.
+func diffReviews(prev, next *LocalBusiness) (changes []ReviewChange) {
+	old := make(map[string]Review, len(prev.Reviews))
+	for _, r := range prev.Reviews {
+		old[r.ID] = r
+	}
+
+	now := time.Now()
+	for _, r := range next.Reviews {
+		o, ok := old[r.ID]
+		if !ok || (o.Rating == r.Rating && o.Description == r.Description) {
+			continue
+		}
+		changes = append(changes, ReviewChange{
+			BusinessID:     next.ID,
+			ReviewID:       r.ID,
+			AuthorID:       r.Author.ID,
+			OldRating:      o.Rating,
+			NewRating:      r.Rating,
+			OldDescription: o.Description,
+			NewDescription: r.Description,
+			DetectedAt:     now,
+		})
+	}
+	return changes
+}
+
+// Snapshot records the state of a business at the time of a scheduled refresh.
+type Snapshot struct {
+	Time            time.Time `json:"time"`
+	AggregateRating float64   `json:"aggregate_rating"`
+	ReviewCount     int       `json:"review_count"`
+	Rating          float64   `json:"rating"`
+	ScrapedReviews  int       `json:"scraped_reviews"`
+	NewReviews      []string  `json:"new_reviews"`
+}
+
+// AppendSnapshot records a snapshot of a business.
+func (s *Store) AppendSnapshot(id string, snap Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var snapshots []Snapshot
+	if err := s.read(s.path("snapshots", id), &snapshots); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return s.write(s.path("snapshots", id), append(snapshots, snap))
+}
+
+// Snapshots returns the snapshots of a business, oldest first.
+func (s *Store) Snapshots(id string) (snapshots []Snapshot, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	err = s.read(s.path("snapshots", id), &snapshots)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return snapshots, err
+}
+
+// ReviewChanges returns the recorded review changes of a business, oldest first.
+func (s *Store) ReviewChanges(id string) (changes []ReviewChange, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	err = s.read(s.path("changes", id), &changes)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return changes, err
+}
+
+// LoadBusiness returns the stored business with the given ID.
+func (s *Store) LoadBusiness(id string) (b LocalBusiness, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	err = s.read(s.path("businesses", id), &b)
+	return b, err
+}
+
+// FindBusiness returns the stored business with the given ID or URL.
+func (s *Store) FindBusiness(idOrURL string) (b LocalBusiness, err error) {
+	if b, err = s.LoadBusiness(idOrURL); err == nil {
+		return b, nil
+	}
+
+	businesses, err := s.Businesses()
+	if err != nil {
+		return b, err
+	}
+	for _, b := range businesses {
+		if b.URL == idOrURL {
+			return b, nil
+		}
+	}
+	return b, fmt.Errorf("business %s not found", idOrURL)
+}
+
+// BusinessIDs returns the IDs of all stored businesses.
+func (s *Store) BusinessIDs() (ids []string, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	files, err := ioutil.ReadDir(filepath.Join(s.dir, "businesses"))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), ".json") {
+			ids = append(ids, strings.TrimSuffix(f.Name(), ".json"))
+		}
+	}
+	return ids, nil
+}
+
+// Businesses returns all stored businesses.
+func (s *Store) Businesses() (businesses []LocalBusiness, err error) {
+	ids, err := s.BusinessIDs()
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		b, err := s.LoadBusiness(id)
+		if err != nil {
+			return nil, err
+		}
+		businesses = append(businesses, b)
+	}
+	return businesses, nil
+}
@@ -0,0 +1,207 @@
+package yelp
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxSummaryCandidates caps the number of sentences ranked, as ranking is
+	// quadratic in the number of sentences.
+	maxSummaryCandidates = 1500
+	// minSummaryWords is the number of content words below which a sentence is
+	// not considered for a summary.
+	minSummaryWords = 4
+	// textRankDamping is the PageRank damping factor.
+	textRankDamping = 0.85
+	// redundantSimilarity is the word overlap beyond which a sentence is
+	// considered a repeat of one already selected.
+	redundantSimilarity = 0.5
+)
+
+// SummarySentence defines a sentence selected for a summary.
+type SummarySentence struct {
+	Text string `json:"text"`
+	// ReviewID is the ID of the review the sentence was taken from.
+	ReviewID  string  `json:"review_id"`
+	Score     float64 `json:"score"`
+	Sentiment float64 `json:"sentiment"`
+}
+
+// SummaryOptions defines the length and layout of a summary.
+type SummaryOptions struct {
+	// Sentences is the number of sentences per list. Defaults to 5.
+	Sentences int `json:"sentences"`
+	// Split separates praise and complaints.
+	Split bool `json:"split"`
+}
+
+// Summary defines the representative sentences of the reviews of a business.
+type Summary struct {
+	Sentences  []SummarySentence `json:"sentences"`
+	Praise     []SummarySentence `json:"praise,omitempty"`
+	Complaints []SummarySentence `json:"complaints,omitempty"`
+}
+
+// summaryCandidate defines a sentence considered for a summary.
+type summaryCandidate struct {
+	SummarySentence
+	words map[string]bool
+}
+
+// summaryCandidates splits the review texts into sentences, most recent
+// reviews first.
+func summaryCandidates(reviews []Review) (candidates []summaryCandidate) {
+	sorted := append([]Review(nil), reviews...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.After(sorted[j].Date)
+	})
+
+	for _, r := range sorted {
+		for _, sentence := range sentenceRe.FindAllString(r.Description, -1) {
+			words := map[string]bool{}
+			for _, w := range tokenize(sentence) {
+				if isTermWord(w) {
+					words[w] = true
+				}
+			}
+			if len(words) < minSummaryWords {
+				continue
+			}
+
+			sentence = strings.TrimSpace(sentence)
+			candidates = append(candidates, summaryCandidate{
+				SummarySentence: SummarySentence{
+					Text:      sentence,
+					ReviewID:  r.ID,
+					Sentiment: SentimentScore(sentence),
+				},
+				words: words,
+			})
+			if len(candidates) == maxSummaryCandidates {
+				return candidates
+			}
+		}
+	}
+	return candidates
+}
+
+func overlap(a, b map[string]bool) (n int) {
+	for w := range a {
+		if b[w] {
+			n++
+		}
+	}
+	return n
+}
+
+// textRankSimilarity is the TextRank similarity of two sentences: their
+// shared words normalized by their lengths.
+func textRankSimilarity(a, b map[string]bool) float64 {
+	n := overlap(a, b)
+	if n == 0 {
+		return 0
+	}
+	return float64(n) / (math.Log(float64(len(a))) + math.Log(float64(len(b))))
+}
+
+// textRank scores the candidates by PageRank over their similarity graph.
+func textRank(candidates []summaryCandidate) {
+	n := len(candidates)
+	weights := make([][]float64, n)
+	totals := make([]float64, n)
+	for i := range weights {
+		weights[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			w := textRankSimilarity(candidates[i].words, candidates[j].words)
+			weights[i][j], weights[j][i] = w, w
+			totals[i] += w
+			totals[j] += w
+		}
+	}
+
+	scores := make([]float64, n)
+	for i := range scores {
+		scores[i] = 1
+	}
+	for iter := 0; iter < 50; iter++ {
+		var delta float64
+		next := make([]float64, n)
+		for i := 0; i < n; i++ {
+			var sum float64
+			for j := 0; j < n; j++ {
+				if weights[j][i] > 0 {
+					sum += weights[j][i] / totals[j] * scores[j]
+				}
+			}
+			next[i] = 1 - textRankDamping + textRankDamping*sum
+			delta += math.Abs(next[i] - scores[i])
+		}
+		scores = next
+		if delta < 1e-4 {
+			break
+		}
+	}
+
+	for i := range candidates {
+		candidates[i].Score = scores[i]
+	}
+}
+
+// selectSentences returns the n best scored candidates, skipping repeats of
+// sentences already selected.
+func selectSentences(candidates []summaryCandidate, n int) []SummarySentence {
+	textRank(candidates)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	selected := []SummarySentence{}
+	var words []map[string]bool
+	for _, c := range candidates {
+		if len(selected) == n {
+			break
+		}
+		redundant := false
+		for _, w := range words {
+			if float64(overlap(c.words, w))/float64(len(c.words)) > redundantSimilarity {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			selected = append(selected, c.SummarySentence)
+			words = append(words, c.words)
+		}
+	}
+	return selected
+}
+
+// Summarize selects representative sentences of the reviews of the business
+// with TextRank. When opts.Split is set, positive and negative sentences are
+// also ranked separately into praise and complaints.
+func (b *LocalBusiness) Summarize(opts SummaryOptions) (summary Summary) {
+	if opts.Sentences <= 0 {
+		opts.Sentences = 5
+	}
+
+	candidates := summaryCandidates(b.Reviews)
+	if opts.Split {
+		var praise, complaints []summaryCandidate
+		for _, c := range candidates {
+			switch {
+			case c.Sentiment >= neutralSentiment:
+				praise = append(praise, c)
+			case c.Sentiment <= -neutralSentiment:
+				complaints = append(complaints, c)
+			}
+		}
+		summary.Praise = selectSentences(praise, opts.Sentences)
+		summary.Complaints = selectSentences(complaints, opts.Sentences)
+	}
+	summary.Sentences = selectSentences(candidates, opts.Sentences)
+	return summary
+}
@@ -0,0 +1,107 @@
+package yelp
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// surgeDeviations is the number of standard deviations above the mean
+	// weekly volume beyond which a week is a surge.
+	surgeDeviations = 3
+	// minSurgeReviews is the minimum volume of a surge week.
+	minSurgeReviews = 3
+)
+
+// PeriodStats defines the rating summary of the reviews of a period.
+type PeriodStats struct {
+	Start time.Time `json:"start"`
+	RatingStats
+}
+
+// VelocityStats defines the review volume of a business over time.
+type VelocityStats struct {
+	ReviewsPerMonth float64 `json:"reviews_per_month"`
+	// RecentReviewsPerMonth is the review velocity over the last 90 days.
+	RecentReviewsPerMonth float64 `json:"recent_reviews_per_month"`
+	// Weekly and Monthly hold every period from the first to the last dated
+	// review, including periods without reviews.
+	Weekly  []PeriodStats `json:"weekly"`
+	Monthly []PeriodStats `json:"monthly"`
+	// DayOfWeek is indexed by weekday, sunday first.
+	DayOfWeek []RatingStats `json:"day_of_week"`
+	// MonthOfYear is indexed by month minus one.
+	MonthOfYear []RatingStats `json:"month_of_year"`
+	// Surges holds the weeks with an unusually high volume of reviews.
+	Surges []PeriodStats `json:"surges"`
+}
+
+// periodSeries summarizes dated reviews, oldest first, per contiguous period.
+func periodSeries(dated []Review, period string, next func(t time.Time) time.Time) (series []PeriodStats) {
+	if len(dated) == 0 {
+		return []PeriodStats{}
+	}
+
+	last, _ := periodStart(dated[len(dated)-1].Date, period)
+	i := 0
+	for start, _ := periodStart(dated[0].Date, period); !start.After(last); start = next(start) {
+		p := PeriodStats{Start: start, RatingStats: RatingStats{Distribution: make([]int, 5)}}
+		end := next(start)
+		for ; i < len(dated) && dated[i].Date.Before(end); i++ {
+			p.add(&dated[i])
+		}
+		series = append(series, p)
+	}
+	return series
+}
+
+// surges returns the periods whose volume exceeds the mean by more than
+// surgeDeviations standard deviations.
+func surges(series []PeriodStats) []PeriodStats {
+	found := []PeriodStats{}
+	if len(series) < 2 {
+		return found
+	}
+
+	var sum, sumSq float64
+	for _, p := range series {
+		sum += float64(p.Count)
+		sumSq += float64(p.Count * p.Count)
+	}
+	n := float64(len(series))
+	mean := sum / n
+	std := math.Sqrt(math.Max(0, sumSq/n-mean*mean))
+	for _, p := range series {
+		if p.Count >= minSurgeReviews && float64(p.Count) > mean+surgeDeviations*std {
+			found = append(found, p)
+		}
+	}
+	return found
+}
+
+// VelocityStats returns the review volume and rating of the business per week
+// and month, its weekly and yearly seasonality, and weeks of unusual volume.
+//
+// Reviews without a date are ignored.
+func (b *LocalBusiness) VelocityStats() (stats VelocityStats) {
+	dated := datedReviews(b.Reviews)
+	stats.ReviewsPerMonth, stats.RecentReviewsPerMonth = reviewVelocity(dated, time.Now(), recentVelocityDays)
+
+	stats.Weekly = periodSeries(dated, "week", func(t time.Time) time.Time { return t.AddDate(0, 0, 7) })
+	stats.Monthly = periodSeries(dated, "month", func(t time.Time) time.Time { return t.AddDate(0, 1, 0) })
+	stats.Surges = surges(stats.Weekly)
+
+	stats.DayOfWeek = make([]RatingStats, 7)
+	stats.MonthOfYear = make([]RatingStats, 12)
+	for i := range stats.DayOfWeek {
+		stats.DayOfWeek[i].Distribution = make([]int, 5)
+	}
+	for i := range stats.MonthOfYear {
+		stats.MonthOfYear[i].Distribution = make([]int, 5)
+	}
+	for i := range dated {
+		stats.DayOfWeek[dated[i].Date.Weekday()].add(&dated[i])
+		stats.MonthOfYear[dated[i].Date.Month()-1].add(&dated[i])
+	}
+	return stats
+}